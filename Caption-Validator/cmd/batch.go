@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"caption-validator/internal/report"
+)
+
+// defaultBatchInclude are the file name patterns of caption files, which
+// are the only files picked up when a batch walks a directory. Generic *.xml
+// is left out, as season folders often hold XML metadata next to the
+// captions; -include can add it for TTML saved as .xml.
+const defaultBatchInclude = "*.srt,*.vtt,*.webvtt,*.ttml,*.dfxp,*.scc"
+
+// batchJob is one file to validate together with the settings that apply to it
+type batchJob struct {
+	path string
+	opts fileOptions
+}
+
+// batchSummary counts the outcomes of a batch run
+type batchSummary struct {
+	Total    int `json:"total"`
+	Passed   int `json:"passed"`
+	Failed   int `json:"failed"`
+	Errors   int `json:"errors"`
+	Missing  int `json:"missing,omitempty"`
+	Unlisted int `json:"unlisted,omitempty"`
+}
+
+// batchReport is the JSON document emitted by the batch subcommand: one
+// report per file, as emitted for a single file, and a summary of them
+type batchReport struct {
+	SchemaVersion string           `json:"schema_version"`
+	Files         []*report.Report `json:"files"`
+	Summary       batchSummary     `json:"summary"`
+}
+
+// runBatch implements the batch subcommand:
+//
+//	caption-validator batch [flags] directory-or-file...
+//	caption-validator batch -manifest manifest.json [flags] [directory...]
+//
+// Directories are walked recursively for files matching -include, and files
+// are validated concurrently. Files named on the command line are validated
+// whatever their name.
+// With a manifest, only the listed files are validated, each with its own
+// settings; files that are listed but absent and files that are present but
+// not listed are reported separately. The exit code follows the same rules
+// as single-file validation, taken over every file in the batch: 3 if any
+// file could not be validated at all (unsupported format, parse error,
+// missing), 4 if the language API failed, and 1 if any file has findings at
+// the -fail-on severity.
+func runBatch(args []string) int {
+	flags := flag.NewFlagSet("batch", flag.ContinueOnError)
+	tStart := flags.String("t_start", "0", "Start time in seconds or HH:MM:SS format")
+	tEnd := flags.String("t_end", "", "End time in seconds or HH:MM:SS format (default: end of the last caption in each file)")
+	language := registerLanguageFlags(flags)
+	workers := flags.Int("workers", runtime.NumCPU(), "Number of files to validate concurrently")
+	rules := registerRuleFlags(flags)
+	outputFormat := registerFormatFlag(flags)
+	failOn := registerFailOnFlag(flags)
+	manifestPath := flags.String("manifest", "", "JSON or CSV manifest listing each file with its own t_start, t_end, coverage, max_gap and language")
+	includeFlag := flags.String("include", defaultBatchInclude, "Comma-separated file name patterns of the files validated in directories")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		log.Printf("Error parsing batch flags: %v\n", err)
+		return exitUsage
+	}
+
+	if flags.NArg() == 0 && *manifestPath == "" {
+		log.Println("Error: batch requires at least one directory or file")
+		return exitUsage
+	}
+	if err := checkOutputFormat(*outputFormat); err != nil {
+		log.Printf("Error: %v\n", err)
+		return exitUsage
+	}
+	if err := checkFailOn(*failOn); err != nil {
+		log.Printf("Error: %v\n", err)
+		return exitUsage
+	}
+
+	include, err := parseIncludePatterns(*includeFlag)
+	if err != nil {
+		log.Printf("Error: %v\n", err)
+		return exitUsage
+	}
+
+	profile, err := rules.profile(flags, language.ruleParams(flags))
+	if err != nil {
+		log.Printf("Error loading profile: %v\n", err)
+		return exitUsage
+	}
+
+	// Ctrl-C stops waiting on the language API, retries included
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	validateLanguage, err := language.validator(ctx)
+	if err != nil {
+		log.Printf("Error: %v\n", err)
+		return exitUsage
+	}
+
+	opts := fileOptions{
+		profile:          profile,
+		validateLanguage: validateLanguage,
+	}
+	if err := language.apply(&opts); err != nil {
+		log.Printf("Error: %v\n", err)
+		return exitUsage
+	}
+
+	opts.startSec, err = parseTimeInput(*tStart)
+	if err != nil {
+		log.Printf("Error parsing t_start: %v\n", err)
+		return exitUsage
+	}
+	if *tEnd != "" {
+		opts.endSec, err = parseTimeInput(*tEnd)
+		if err != nil {
+			log.Printf("Error parsing t_end: %v\n", err)
+			return exitUsage
+		}
+	}
+
+	var files []*report.Report
+	if *manifestPath != "" {
+		files, err = runManifestBatch(*manifestPath, flags.Args(), include, opts, *workers)
+	} else {
+		var paths []string
+		paths, err = collectBatchFiles(flags.Args(), include)
+		if err == nil {
+			jobs := make([]batchJob, len(paths))
+			for i, path := range paths {
+				jobs[i] = batchJob{path: path, opts: opts}
+			}
+			log.Printf("Batch validating %d files with %d workers\n", len(jobs), *workers)
+			files = validateBatch(jobs, *workers)
+		}
+	}
+	if err != nil {
+		log.Printf("Error collecting caption files: %v\n", err)
+		if errors.Is(err, errInvalidManifest) {
+			return exitUsage
+		}
+		return exitIO
+	}
+
+	batch := summariseBatch(files)
+
+	if *outputFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(batch)
+	} else {
+		err = writeReports(os.Stdout, *outputFormat, files)
+	}
+	if err != nil {
+		log.Printf("Error writing batch report: %v\n", err)
+		return exitIO
+	}
+
+	log.Printf("Batch complete: %d passed, %d failed, %d errors\n",
+		batch.Summary.Passed, batch.Summary.Failed, batch.Summary.Errors)
+
+	return exitCode(files, *failOn)
+}
+
+// summariseBatch wraps the per-file reports of a batch and counts them
+func summariseBatch(files []*report.Report) batchReport {
+	batch := batchReport{SchemaVersion: report.SchemaVersion, Files: files}
+	for _, file := range files {
+		batch.Summary.Total++
+		switch {
+		case file.Error != nil && file.Error.Type == "missing_file":
+			batch.Summary.Missing++
+		case file.Error != nil && file.Error.Type == "unlisted_file":
+			batch.Summary.Unlisted++
+		case file.Error != nil:
+			batch.Summary.Errors++
+		case file.Summary.Passed:
+			batch.Summary.Passed++
+		default:
+			batch.Summary.Failed++
+		}
+	}
+	return batch
+}
+
+// runManifestBatch validates the files listed in a manifest with their own
+// settings. Files under roots (default: the manifest's directory) that match
+// include but that the manifest does not list are reported as unlisted_file.
+func runManifestBatch(manifestPath string, roots []string, include []string, defaults fileOptions, workers int) ([]*report.Report, error) {
+	entries, err := loadManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	manifestDir := filepath.Dir(manifestPath)
+
+	var jobs []batchJob
+	var results []*report.Report
+	missing := make(map[int]bool)
+	listed := map[string]bool{absPath(manifestPath): true}
+
+	for _, entry := range entries {
+		path, opts, err := entry.resolve(manifestDir, defaults)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", errInvalidManifest, manifestPath, err)
+		}
+		listed[absPath(path)] = true
+
+		if _, err := os.Stat(path); err != nil {
+			missing[len(jobs)] = true
+		}
+		jobs = append(jobs, batchJob{path: path, opts: opts})
+	}
+
+	// Only files that exist are handed to the workers
+	var present []batchJob
+	for i, job := range jobs {
+		if !missing[i] {
+			present = append(present, job)
+		}
+	}
+	log.Printf("Batch validating %d manifest entries with %d workers\n", len(present), workers)
+	validated := validateBatch(present, workers)
+
+	for i, job := range jobs {
+		if missing[i] {
+			log.Printf("Manifest file not found: %s\n", job.path)
+			results = append(results, failedReport(job.path, job.opts, "missing_file", "File listed in manifest was not found"))
+			continue
+		}
+		results = append(results, validated[0])
+		validated = validated[1:]
+	}
+
+	if len(roots) == 0 {
+		roots = []string{manifestDir}
+	}
+	paths, err := collectBatchFiles(roots, include)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		if !listed[absPath(path)] {
+			log.Printf("File not listed in manifest: %s\n", path)
+			results = append(results, failedReport(path, defaults, "unlisted_file", "File is not listed in the manifest"))
+		}
+	}
+
+	return results, nil
+}
+
+// failedReport returns the report of a file that was not validated
+func failedReport(path string, opts fileOptions, errorType string, message string) *report.Report {
+	rep := report.New(path, report.Params{
+		StartTime:        opts.startSec,
+		EndTime:          opts.endSec,
+		ExpectedLanguage: opts.expectedLang,
+		Profile:          opts.profile,
+	})
+	rep.Fail(errorType, message)
+	return rep
+}
+
+// absPath returns a cleaned absolute form of path for comparisons
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// parseIncludePatterns splits the -include flag into file name patterns
+func parseIncludePatterns(value string) ([]string, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid -include pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	if len(patterns) == 0 {
+		return nil, errors.New("-include needs at least one pattern")
+	}
+	return patterns, nil
+}
+
+// includedFile reports whether a file name matches any of the patterns,
+// ignoring case. No patterns include every file.
+func includedFile(name string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// collectBatchFiles expands the given paths into a sorted list of files,
+// walking directories recursively and skipping hidden files and directories.
+// Files found in directories are kept only if their name matches include;
+// files given as roots are always kept.
+func collectBatchFiles(roots []string, include []string) ([]string, error) {
+	var paths []string
+
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Type().IsRegular() && (path == root || includedFile(d.Name(), include)) {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// validateBatch validates files with a bounded pool of workers. Results are
+// returned in the same order as jobs.
+func validateBatch(jobs []batchJob, workers int) []*report.Report {
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]*report.Report, len(jobs))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = validateFile(jobs[i].path, jobs[i].opts)
+			}
+		}()
+	}
+
+	for i := range jobs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	return results
+}
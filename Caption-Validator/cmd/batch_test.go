@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"caption-validator/internal/client"
+	"caption-validator/internal/report"
+	"caption-validator/internal/validator"
+)
+
+func TestCollectBatchFiles(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		"season1/episode2.vtt",
+		"season1/episode1.srt",
+		"season2/extras/episode3.vtt",
+		"notes.txt",
+		"README",
+		"caption-validator.log",
+		"season1/EPISODE4.SCC",
+		"season1/episode5.srt.bak",
+		"season1/metadata.xml",
+		".hidden.vtt",
+		".git/config",
+	}
+	for _, name := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	include, err := parseIncludePatterns(defaultBatchInclude)
+	if err != nil {
+		t.Fatalf("parseIncludePatterns returned error: %v", err)
+	}
+
+	tests := []struct {
+		roots   []string
+		include []string
+		want    []string
+	}{
+		// Only caption files are picked up from directories
+		{[]string{root}, include, []string{"season1/EPISODE4.SCC", "season1/episode1.srt", "season1/episode2.vtt", "season2/extras/episode3.vtt"}},
+		// but a file named on the command line is always validated
+		{[]string{filepath.Join(root, "notes.txt"), filepath.Join(root, "season2")}, include, []string{"notes.txt", "season2/extras/episode3.vtt"}},
+		{[]string{root}, []string{"*.txt", "readme"}, []string{"README", "notes.txt"}},
+		{[]string{filepath.Join(root, "season1")}, nil, []string{"season1/EPISODE4.SCC", "season1/episode1.srt", "season1/episode2.vtt", "season1/episode5.srt.bak", "season1/metadata.xml"}},
+		// TTML saved as .xml is picked up when asked for
+		{[]string{filepath.Join(root, "season1")}, append(include, "*.xml"), []string{"season1/EPISODE4.SCC", "season1/episode1.srt", "season1/episode2.vtt", "season1/metadata.xml"}},
+	}
+	for _, tt := range tests {
+		paths, err := collectBatchFiles(tt.roots, tt.include)
+		if err != nil {
+			t.Fatalf("collectBatchFiles returned error: %v", err)
+		}
+
+		var got []string
+		for _, path := range paths {
+			rel, _ := filepath.Rel(root, path)
+			got = append(got, filepath.ToSlash(rel))
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("collectBatchFiles(%v) = %v, want %v", tt.include, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", " , ", "[a-"} {
+		if _, err := parseIncludePatterns(value); err == nil {
+			t.Errorf("Expected an error for -include %q", value)
+		}
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	root := t.TempDir()
+	contents := map[string]string{
+		"full.vtt":       "WEBVTT\n\n1\n00:00:00.000 --> 00:01:00.000\nHello there\n",
+		"partial.srt":    "1\n00:00:00,000 --> 00:00:10,000\nHello there\n",
+		"spanish.vtt":    "WEBVTT\n\n1\n00:00:00.000 --> 00:01:00.000\nHola amigos\n",
+		"it's \"q\".vtt": "WEBVTT\n\n1\n00:00:00.000 --> 00:01:00.000\nQuoted file name\n",
+		"notes.txt":      "Not a caption file",
+	}
+	var paths []string
+	for name, content := range contents {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		body := make([]byte, 512)
+		n, _ := r.Body.Read(body)
+		if strings.Contains(string(body[:n]), "Hola") {
+			w.Write([]byte(`{"lang": "es-ES"}`))
+			return
+		}
+		w.Write([]byte(`{"lang": "en-US"}`))
+	}))
+	defer server.Close()
+
+	profile := validator.Profile{Rules: []validator.RuleConfig{
+		{Rule: "coverage", Params: validator.Params{"min_coverage": 90.0}},
+		{Rule: "line_length", Severity: "warning", Params: validator.Params{"max_chars_per_line": 10}},
+		{Rule: "language"},
+	}}
+	opts := fileOptions{
+		endSec:           60,
+		profile:          profile,
+		validateLanguage: languageValidator(context.Background(), client.NewHTTPDetector(nil, server.URL)),
+	}
+	jobs := make([]batchJob, len(paths))
+	for i, path := range paths {
+		jobs[i] = batchJob{path: path, opts: opts}
+	}
+	results := validateBatch(jobs, 3)
+
+	byName := make(map[string]*report.Report)
+	for i, result := range results {
+		if result.File != paths[i] {
+			t.Errorf("Result %d is for %s, want %s", i, result.File, paths[i])
+		}
+		byName[filepath.Base(result.File)] = result
+	}
+
+	if r := byName["full.vtt"]; !r.Summary.Passed || r.Error != nil || len(r.Findings) != 1 || r.Findings[0].Severity != validator.SeverityWarning {
+		t.Errorf("full.vtt should pass with a line length warning, got %+v", r)
+	}
+	if r := byName["it's \"q\".vtt"]; !r.Summary.Passed {
+		t.Errorf("File with quotes in its name should pass, got %+v", r)
+	}
+	if r := byName["partial.srt"]; r.Summary.Passed || r.Summary.Errors != 1 || r.Findings[0].Type != "caption_coverage" {
+		t.Errorf("partial.srt should fail coverage, got %+v", r)
+	}
+	if r := byName["spanish.vtt"]; r.Summary.Passed || len(r.Findings) != 2 || r.Findings[1].Type != "incorrect_language" {
+		t.Errorf("spanish.vtt should fail language, got %+v", r)
+	}
+	if r := byName["notes.txt"]; r.Error == nil || r.Error.Type != "unsupported_format" {
+		t.Errorf("notes.txt should be reported as unsupported, got %+v", r)
+	}
+	if requests != 4 {
+		t.Errorf("Expected 4 language API requests, got %d", requests)
+	}
+
+	// The whole report must be well-formed JSON regardless of file names
+	data, err := json.Marshal(summariseBatch(results))
+	if err != nil {
+		t.Fatalf("Failed to marshal batch report: %v", err)
+	}
+	var decoded batchReport
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Batch report is not valid JSON: %v", err)
+	}
+	if decoded.Summary.Passed != 2 || decoded.Summary.Failed != 2 || decoded.Summary.Errors != 1 {
+		t.Errorf("Unexpected batch summary: %+v", decoded.Summary)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"caption-validator/internal/parser"
+)
+
+// runConvert implements the convert subcommand:
+//
+//	caption-validator convert [-to format] input-filepath output-filepath
+//
+// Conversion uses the same parsers as validation, so the validator and the
+// converter never disagree about timing or text.
+func runConvert(args []string) int {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	toFormat := flags.String("to", "", "Output format: vtt, srt, ttml or scc (default: from output file extension)")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		log.Printf("Error parsing convert flags: %v\n", err)
+		return exitUsage
+	}
+
+	if flags.NArg() != 2 {
+		log.Println("Error: convert requires an input and an output file path")
+		return exitUsage
+	}
+	inputPath, outputPath := flags.Arg(0), flags.Arg(1)
+
+	// Resolve the output format from -to, falling back to the output extension
+	formatName := *toFormat
+	if formatName == "" {
+		formatName = outputPath
+	}
+	outputFormat, ok := parser.LookupFormat(formatName)
+	if !ok {
+		log.Printf("Error: Unknown output format: %s\n", formatName)
+		fmt.Printf("{\"type\": \"unsupported_format\", \"file\": %q, \"error\": \"Unsupported output caption format\"}\n", outputPath)
+		return exitUsage
+	}
+
+	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
+		log.Printf("Error: Captions file does not exist: %s\n", inputPath)
+		fmt.Printf("{\"type\": \"file_not_found\", \"file\": %q, \"error\": \"Caption file not found\"}\n", inputPath)
+		return exitIO
+	}
+
+	captions, inputFormat, err := parser.ParseCaptionsFile(inputPath)
+	if err != nil {
+		if errors.Is(err, parser.ErrUnsupportedFormat) {
+			log.Printf("Error: Unsupported caption format for file: %s\n", inputPath)
+			fmt.Printf("{\"type\": \"unsupported_format\", \"file\": %q, \"error\": \"Unsupported caption file format\"}\n", inputPath)
+			return exitIO
+		}
+		if errors.Is(err, parser.ErrAmbiguousFormat) {
+			log.Printf("Error: %v: %s\n", err, inputPath)
+			fmt.Printf("{\"type\": \"ambiguous_format\", \"file\": %q, \"error\": %q}\n", inputPath, err.Error())
+			return exitIO
+		}
+		log.Printf("Error parsing captions file: %v\n", err)
+		return exitIO
+	}
+
+	out, err := os.Create(outputPath)
+	if err != nil {
+		log.Printf("Error creating output file: %v\n", err)
+		return exitIO
+	}
+
+	if err := parser.WriteCaptions(out, captions, outputFormat); err != nil {
+		out.Close()
+		log.Printf("Error writing %s output: %v\n", outputFormat, err)
+		return exitIO
+	}
+	if err := out.Close(); err != nil {
+		log.Printf("Error writing %s output: %v\n", outputFormat, err)
+		return exitIO
+	}
+
+	log.Printf("Converted %d captions from %s (%s) to %s (%s)\n",
+		len(captions), inputPath, inputFormat, outputPath, outputFormat)
+	return exitOK
+}
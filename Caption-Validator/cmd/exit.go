@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"caption-validator/internal/report"
+	"caption-validator/internal/validator"
+)
+
+// Exit codes, shared by every subcommand. When a batch hits several kinds of
+// problem, the lowest non-zero code other than exitFailed wins, so a broken
+// setup is never mistaken for a policy failure.
+const (
+	exitOK      = 0 // every file validated without findings at the -fail-on severity
+	exitFailed  = 1 // validation findings at or above the -fail-on severity
+	exitUsage   = 2 // bad flags, arguments, profile or manifest
+	exitIO      = 3 // a caption file is missing, unreadable, unsupported or malformed
+	exitService = 4 // an external service, such as the language API, failed with -lang-strict
+)
+
+// failOnLevels maps the values of -fail-on to the severities that fail a run
+var failOnLevels = map[string]map[validator.Severity]bool{
+	"error":   {validator.SeverityError: true},
+	"warning": {validator.SeverityError: true, validator.SeverityWarning: true},
+	"none":    {},
+}
+
+// registerFailOnFlag defines the -fail-on flag on a flag set
+func registerFailOnFlag(flags *flag.FlagSet) *string {
+	return flags.String("fail-on", "error", "Lowest finding severity that makes the exit code non-zero: error, warning or none")
+}
+
+// checkFailOn rejects unknown -fail-on values
+func checkFailOn(failOn string) error {
+	if _, ok := failOnLevels[failOn]; !ok {
+		return fmt.Errorf("unknown -fail-on value %q (want error, warning or none)", failOn)
+	}
+	return nil
+}
+
+// exitCode decides the exit code of a run from its reports
+func exitCode(reports []*report.Report, failOn string) int {
+	failing := failOnLevels[failOn]
+	code := exitOK
+
+	worse := func(candidate int) {
+		if code == exitOK || code == exitFailed || (candidate != exitFailed && candidate < code) {
+			code = candidate
+		}
+	}
+
+	for _, r := range reports {
+		if r.Error != nil {
+			switch r.Error.Type {
+			case "validation_error":
+				// A rule rejected its input, e.g. t_end before t_start
+				worse(exitUsage)
+			case "service_error":
+				// The language API failed with -lang-strict. Without it the
+				// rule is only skipped, which summary.service_errors counts
+				// but the exit code ignores.
+				worse(exitService)
+			case "unlisted_file":
+				// Reported for information only
+			default:
+				worse(exitIO)
+			}
+		}
+		for _, finding := range r.Findings {
+			if failing[finding.Severity] {
+				worse(exitFailed)
+				break
+			}
+		}
+	}
+
+	return code
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"caption-validator/internal/report"
+	"caption-validator/internal/validator"
+)
+
+func TestExitCode(t *testing.T) {
+	withFinding := func(severity validator.Severity) *report.Report {
+		rep := report.New("a.vtt", report.Params{})
+		rep.Findings = []report.Finding{{Rule: "line_length", Severity: severity}}
+		return rep
+	}
+	withError := func(errorType string) *report.Report {
+		rep := report.New("a.vtt", report.Params{})
+		rep.Fail(errorType, "failed")
+		return rep
+	}
+	serviceError := report.New("a.vtt", report.Params{})
+	serviceError.Summary.ServiceErrors = 1
+
+	tests := []struct {
+		name    string
+		reports []*report.Report
+		failOn  string
+		want    int
+	}{
+		{"clean", []*report.Report{report.New("a.vtt", report.Params{})}, "error", exitOK},
+		{"error finding", []*report.Report{withFinding(validator.SeverityError)}, "error", exitFailed},
+		{"warning finding", []*report.Report{withFinding(validator.SeverityWarning)}, "error", exitOK},
+		{"warning finding with fail-on warning", []*report.Report{withFinding(validator.SeverityWarning)}, "warning", exitFailed},
+		{"info finding with fail-on warning", []*report.Report{withFinding(validator.SeverityInfo)}, "warning", exitOK},
+		{"error finding with fail-on none", []*report.Report{withFinding(validator.SeverityError)}, "none", exitOK},
+		{"parse error", []*report.Report{withError("parse_error")}, "none", exitIO},
+		{"missing file", []*report.Report{withError("missing_file")}, "error", exitIO},
+		{"unlisted file", []*report.Report{withError("unlisted_file")}, "error", exitOK},
+		{"invalid rule input", []*report.Report{withError("validation_error")}, "error", exitUsage},
+		// Without -lang-strict a failed language API only skips the rule
+		{"skipped service", []*report.Report{serviceError}, "error", exitOK},
+		{"failure and skipped service", []*report.Report{withFinding(validator.SeverityError), serviceError}, "error", exitFailed},
+		{"strict service error", []*report.Report{withError("service_error")}, "none", exitService},
+		{"failure then strict service error", []*report.Report{withFinding(validator.SeverityError), withError("service_error")}, "error", exitService},
+		{"strict service error then parse error", []*report.Report{withError("service_error"), withError("parse_error")}, "error", exitIO},
+		{"parse error then invalid rule input", []*report.Report{withError("parse_error"), withError("validation_error")}, "error", exitUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.reports, tt.failOn); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckFailOn(t *testing.T) {
+	for _, value := range []string{"error", "warning", "none"} {
+		if err := checkFailOn(value); err != nil {
+			t.Errorf("checkFailOn(%q) returned error: %v", value, err)
+		}
+	}
+	if err := checkFailOn("info"); err == nil {
+		t.Error("Expected error for unknown -fail-on value")
+	}
+}
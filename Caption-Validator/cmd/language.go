@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"caption-validator/internal/client"
+	"caption-validator/internal/langid"
+	"caption-validator/internal/validator"
+)
+
+// languageBackend is a value of -lang-detector
+type languageBackend struct {
+	// build returns the detector for the command line settings. A nil
+	// detector skips the language rule.
+	build func(lf languageFlags) client.LanguageDetector
+	// match is the matching mode used when -lang-match is not set, for
+	// detectors that report a language without its region
+	match client.LanguageMatch
+}
+
+// languageDetectors are the values accepted by -lang-detector
+var languageDetectors = map[string]languageBackend{
+	"http": {build: func(lf languageFlags) client.LanguageDetector {
+		if *lf.apiURL == "" {
+			return nil
+		}
+		// One HTTP client and circuit breaker for the whole run, so
+		// connections are reused across files and an API that is down stops
+		// being called for all of them
+		httpClient := client.NewHTTPClient()
+		httpClient.Timeout = *lf.timeout
+		detector := client.NewHTTPDetector(httpClient, *lf.apiURL)
+		detector.Retry.MaxRetries = *lf.retries
+		detector.Breaker = client.NewCircuitBreaker(*lf.breaker, *lf.cooldown)
+		return detector
+	}},
+	// The built-in detector works offline and reports bare language
+	// subtags such as "fr" or "en", with no region, so by default it
+	// accepts any region of the expected language
+	"builtin": {build: func(lf languageFlags) client.LanguageDetector {
+		return langid.New()
+	}, match: client.MatchPrefix},
+}
+
+// languageFlags holds the flags that configure language detection
+type languageFlags struct {
+	detector     *string
+	apiURL       *string
+	expect       *string
+	match        *string
+	fromFilename *bool
+	timeout      *time.Duration
+	retries      *int
+	breaker      *int
+	cooldown     *time.Duration
+	strict       *bool
+	mode         *string
+	window       *float64
+	allow        *string
+}
+
+// registerLanguageFlags defines the language detection flags on a flag set
+func registerLanguageFlags(flags *flag.FlagSet) languageFlags {
+	return languageFlags{
+		detector:     flags.String("lang-detector", "http", "Language detector: http (the -api service) or builtin (offline, reports the language without a region)"),
+		apiURL:       flags.String("api", "http://localhost:8080/validate", "URL of the language validation API used by the http detector (empty: skip language validation)"),
+		expect:       flags.String("expect-lang", "", "BCP 47 tag of the language the captions should be in, e.g. en-GB or fr (default: from the file name, else the profile's, else en-US)"),
+		match:        flags.String("lang-match", "", "How closely the detected language must match: exact, prefix (en accepts en-GB) or language (en-US accepts en-GB) (default: prefix for the builtin detector, else the profile's, else exact)"),
+		fromFilename: flags.Bool("lang-from-filename", true, "Infer the expected language from file names like episode1.fr.vtt"),
+		timeout:      flags.Duration("api-timeout", client.DefaultTimeout, "Time limit of each request to the language API"),
+		retries:      flags.Int("api-retries", client.DefaultRetryPolicy.MaxRetries, "Times a language API request failing with a network error or a 408, 429, 502, 503 or 504 status is retried, with exponential backoff"),
+		breaker:      flags.Int("api-breaker", 5, "Stop calling the language API for -api-breaker-cooldown after this many consecutive failed requests (0 never stops)"),
+		cooldown:     flags.Duration("api-breaker-cooldown", 30*time.Second, "How long the language API is left alone once -api-breaker trips"),
+		strict:       flags.Bool("lang-strict", false, "Fail files whose language could not be checked because the language API is unavailable, instead of skipping the language rule"),
+		mode:         flags.String("lang-mode", "file", "Detect the language of the whole file, of every cue or of every -lang-window: file, cue or window"),
+		window:       flags.Float64("lang-window", 30, "Seconds of captions checked together by -lang-mode window"),
+		allow:        flags.String("lang-allow", "", "Markup whose text may be in another language: italics and/or foreign, comma separated, or empty to check all text (default: foreign with -lang-mode cue or window, none with file)"),
+	}
+}
+
+// apply validates the expected language settings and sets them on opts
+func (lf languageFlags) apply(opts *fileOptions) error {
+	if *lf.expect != "" {
+		tag, err := client.ParseLanguageTag(*lf.expect)
+		if err != nil {
+			return fmt.Errorf("invalid -expect-lang: %w", err)
+		}
+		opts.expectedLang = tag.String()
+	}
+	if *lf.match != "" {
+		match, err := client.ParseLanguageMatch(*lf.match)
+		if err != nil {
+			return fmt.Errorf("invalid -lang-match: %w", err)
+		}
+		opts.languageMatch = match
+	} else {
+		opts.languageMatch = languageDetectors[*lf.detector].match
+	}
+	opts.inferLanguage = *lf.fromFilename
+	opts.strictLanguage = *lf.strict
+	return nil
+}
+
+// ruleParams returns the language rule's params for the -lang-mode,
+// -lang-window and -lang-allow flags, or nil when they are all left at their
+// defaults. -lang-allow is passed on only when given, as the rule's default
+// depends on the mode.
+func (lf languageFlags) ruleParams(flags *flag.FlagSet) validator.Params {
+	params := validator.Params{}
+	if *lf.mode != "file" {
+		params["mode"] = *lf.mode
+	}
+	if *lf.mode == "window" {
+		params["window"] = *lf.window
+	}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "lang-allow" {
+			params["allow"] = *lf.allow
+		}
+	})
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+// languageDetectorNames lists the values of -lang-detector
+func languageDetectorNames() []string {
+	names := make([]string, 0, len(languageDetectors))
+	for name := range languageDetectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validator builds the selected detector and returns the function the
+// language rule calls, or nil when language validation is disabled. Calls
+// in progress give up when ctx is cancelled.
+func (lf languageFlags) validator(ctx context.Context) (func(string, string) (client.LanguageValidationResult, error), error) {
+	backend, ok := languageDetectors[*lf.detector]
+	if !ok {
+		return nil, fmt.Errorf("unknown language detector %q (want one of %s)", *lf.detector, strings.Join(languageDetectorNames(), ", "))
+	}
+	switch {
+	case *lf.timeout <= 0:
+		return nil, fmt.Errorf("-api-timeout must be greater than zero")
+	case *lf.retries < 0:
+		return nil, fmt.Errorf("-api-retries must not be negative")
+	case *lf.breaker < 0:
+		return nil, fmt.Errorf("-api-breaker must not be negative")
+	}
+	return languageValidator(ctx, backend.build(lf)), nil
+}
+
+// languageValidator returns the function the language rule calls with
+// detector, or nil (which skips the rule) when there is no detector
+func languageValidator(ctx context.Context, detector client.LanguageDetector) func(string, string) (client.LanguageValidationResult, error) {
+	if detector == nil {
+		return nil
+	}
+	return func(text string, expectedLang string) (client.LanguageValidationResult, error) {
+		return client.ValidateLanguageContext(ctx, detector, text, expectedLang)
+	}
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"caption-validator/internal/client"
+	"caption-validator/internal/report"
+	"caption-validator/internal/validator"
+)
+
+func TestLanguageFlags(t *testing.T) {
+	parse := func(args ...string) languageFlags {
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		lf := registerLanguageFlags(flags)
+		if err := flags.Parse(args); err != nil {
+			t.Fatalf("Failed to parse flags: %v", err)
+		}
+		return lf
+	}
+
+	if validate, err := parse().validator(context.Background()); err != nil || validate == nil {
+		t.Errorf("Expected the http detector by default, got %v", err)
+	}
+	if validate, err := parse("-api", "").validator(context.Background()); err != nil || validate != nil {
+		t.Errorf("Expected language validation to be disabled without an API, got %v", err)
+	}
+	if _, err := parse("-lang-detector", "oracle").validator(context.Background()); err == nil {
+		t.Error("Expected an error for an unknown detector")
+	}
+
+	var opts fileOptions
+	if err := parse("-expect-lang", "en_gb", "-lang-match", "prefix").apply(&opts); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if opts.expectedLang != "en-GB" || opts.languageMatch != client.MatchPrefix || !opts.inferLanguage {
+		t.Errorf("Unexpected options: %+v", opts)
+	}
+
+	// The built-in detector needs no API and only reports the language
+	builtin := parse("-lang-detector", "builtin", "-api", "")
+	validate, err := builtin.validator(context.Background())
+	if err != nil || validate == nil {
+		t.Fatalf("Expected the builtin detector to work without an API, got %v", err)
+	}
+	opts = fileOptions{}
+	if err := builtin.apply(&opts); err != nil || opts.languageMatch != client.MatchPrefix {
+		t.Errorf("Expected prefix matching by default for the builtin detector, got %+v, %v", opts, err)
+	}
+	result, err := validate("Where are you going? I told you to wait for me here.", "en-US")
+	if err != nil || result.Language != "en" || result.Confidence == 0 {
+		t.Errorf("Unexpected builtin detection: %+v, %v", result, err)
+	}
+
+	for _, args := range [][]string{{"-expect-lang", "English"}, {"-lang-match", "fuzzy"}} {
+		if err := parse(args...).apply(&fileOptions{}); err == nil {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
+
+// staticDetector always detects the same language
+type staticDetector string
+
+func (d staticDetector) DetectLanguage(text string) (client.LanguageDetection, error) {
+	return client.LanguageDetection{Language: string(d)}, nil
+}
+
+func TestLanguageValidator(t *testing.T) {
+	if languageValidator(context.Background(), nil) != nil {
+		t.Error("Expected no validator without a detector")
+	}
+
+	result, err := languageValidator(context.Background(), staticDetector("fr-FR"))("Bonjour", "en-US")
+	if err != nil || result.Valid || result.Language != "fr-FR" {
+		t.Errorf("Expected fr-FR to fail against en-US, got %+v, %v", result, err)
+	}
+}
+
+func TestExpectedLanguage(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "episode1.fr.vtt")
+	if err := os.WriteFile(path, []byte("WEBVTT\n\n00:00:00.000 --> 00:00:10.000\nBonjour\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	base := fileOptions{
+		endSec:           10,
+		profile:          validator.Profile{Rules: []validator.RuleConfig{{Rule: "language"}}},
+		validateLanguage: languageValidator(context.Background(), staticDetector("fr-FR")),
+		inferLanguage:    true,
+	}
+
+	tests := []struct {
+		name     string
+		opts     func(fileOptions) fileOptions
+		expected string
+		passed   bool
+	}{
+		{"From the file name", func(o fileOptions) fileOptions { return o }, "fr", false},
+		{"From the file name with prefix matching", func(o fileOptions) fileOptions {
+			o.languageMatch = client.MatchPrefix
+			return o
+		}, "fr", true},
+		{"Explicit language wins", func(o fileOptions) fileOptions {
+			o.expectedLang = "fr-FR"
+			o.languageMatch = client.MatchLanguage
+			return o
+		}, "fr-FR", true},
+		{"Inference disabled", func(o fileOptions) fileOptions {
+			o.inferLanguage = false
+			return o
+		}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep := validateFile(path, tt.opts(base))
+			if rep.Error != nil || rep.Params.ExpectedLanguage != tt.expected || rep.Summary.Passed != tt.passed {
+				t.Errorf("Expected %q (passed %v), got %q (passed %v, error %+v)",
+					tt.expected, tt.passed, rep.Params.ExpectedLanguage, rep.Summary.Passed, rep.Error)
+			}
+		})
+	}
+
+	if _, _, err := (manifestEntry{File: "a.vtt", Language: "French"}).resolve(root, base); err == nil {
+		t.Error("Expected an error for an invalid manifest language")
+	}
+}
+
+func TestLanguageModeFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "episode1.en.vtt")
+	vtt := `WEBVTT
+
+00:00:00.000 --> 00:00:04.000
+Where are you going? I told you to wait for me here.
+
+00:00:05.000 --> 00:00:09.000
+Je ne sais pas ce que tu veux dire, je suis fatigué.
+
+00:00:10.000 --> 00:00:14.000
+Nous devons partir maintenant, avant qu'il ne soit trop tard.
+
+00:00:15.000 --> 00:00:19.000
+<c.foreign>Bonjour monsieur, comment allez-vous aujourd'hui?</c>
+
+00:00:20.000 --> 00:00:24.000
+Come on, we have to get to the station before the last train leaves.
+`
+	if err := os.WriteFile(path, []byte(vtt), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	rf := registerRuleFlags(flags)
+	lf := registerLanguageFlags(flags)
+	if err := flags.Parse([]string{"-lang-mode", "cue", "-lang-detector", "builtin"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	profile, err := rf.profile(flags, lf.ruleParams(flags))
+	if err != nil {
+		t.Fatalf("profile() error = %v", err)
+	}
+	validate, err := lf.validator(context.Background())
+	if err != nil {
+		t.Fatalf("validator() error = %v", err)
+	}
+	opts := fileOptions{endSec: 24, profile: profile, validateLanguage: validate, inferLanguage: true}
+	if err := lf.apply(&opts); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+
+	// The two French cues are one range; the cue marked foreign is allowed
+	rep := validateFile(path, opts)
+	var ranges []validator.LanguageRange
+	for _, finding := range rep.Findings {
+		if finding.Rule == "language" {
+			ranges = append(ranges, finding.Details.(validator.LanguageRange))
+		}
+	}
+	if len(ranges) != 1 || ranges[0].Detected != "fr" || ranges[0].FirstIndex != 2 || ranges[0].LastIndex != 3 {
+		t.Errorf("Expected one French range over cues 2 and 3, got %+v", ranges)
+	}
+
+	flags = flag.NewFlagSet("test", flag.ContinueOnError)
+	rf = registerRuleFlags(flags)
+	lf = registerLanguageFlags(flags)
+	if err := flags.Parse([]string{"-lang-mode", "scene"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if _, err := rf.profile(flags, lf.ruleParams(flags)); err == nil {
+		t.Error("Expected an error for an unknown language mode")
+	}
+
+	// -lang-allow is passed on only when given, even empty, so that the rule
+	// picks the default of its mode
+	for _, tt := range []struct {
+		args []string
+		want validator.Params
+	}{
+		{nil, nil},
+		{[]string{"-lang-allow="}, validator.Params{"allow": ""}},
+		{[]string{"-lang-mode", "cue", "-lang-allow", "italics"}, validator.Params{"mode": "cue", "allow": "italics"}},
+	} {
+		flags = flag.NewFlagSet("test", flag.ContinueOnError)
+		lf = registerLanguageFlags(flags)
+		if err := flags.Parse(tt.args); err != nil {
+			t.Fatalf("Failed to parse flags: %v", err)
+		}
+		if got := lf.ruleParams(flags); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ruleParams(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestLanguageAPIUnavailable(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	var jobs []batchJob
+	for i := 0; i < 6; i++ {
+		path := filepath.Join(root, fmt.Sprintf("episode%d.vtt", i))
+		if err := os.WriteFile(path, []byte("WEBVTT\n\n00:00:00.000 --> 00:00:10.000\nHello there\n"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		jobs = append(jobs, batchJob{path: path})
+	}
+
+	run := func(jobs []batchJob, args ...string) []*report.Report {
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		lf := registerLanguageFlags(flags)
+		if err := flags.Parse(append([]string{"-api", server.URL, "-api-retries", "1"}, args...)); err != nil {
+			t.Fatalf("Failed to parse flags: %v", err)
+		}
+		validate, err := lf.validator(context.Background())
+		if err != nil {
+			t.Fatalf("validator() error = %v", err)
+		}
+		opts := fileOptions{
+			endSec:           10,
+			profile:          validator.Profile{Rules: []validator.RuleConfig{{Rule: "language"}}},
+			validateLanguage: validate,
+		}
+		if err := lf.apply(&opts); err != nil {
+			t.Fatalf("apply() error = %v", err)
+		}
+		for i := range jobs {
+			jobs[i].opts = opts
+		}
+		requests.Store(0)
+		return validateBatch(jobs, 1)
+	}
+
+	// Every file is retried once and skipped, without failing
+	reports := run(jobs[:2], "-api-breaker", "0")
+	if requests.Load() != 4 {
+		t.Errorf("Expected 2 requests per file, got %d", requests.Load())
+	}
+	for _, r := range reports {
+		if !r.Summary.Passed || r.Summary.ServiceErrors != 1 || r.Rules[0].Status != report.StatusSkipped {
+			t.Errorf("Expected %s to pass with the language rule skipped, got %+v", r.File, r.Summary)
+		}
+	}
+	if code := exitCode(reports, "error"); code != exitOK {
+		t.Errorf("exitCode() = %d, want %d without -lang-strict", code, exitOK)
+	}
+
+	// The breaker is shared by the batch and opens after two failed files;
+	// in strict mode every file fails
+	reports = run(jobs, "-api-breaker", "2", "-api-breaker-cooldown", "1h", "-lang-strict")
+	if requests.Load() != 4 {
+		t.Errorf("Expected the breaker to stop requests after two files, got %d", requests.Load())
+	}
+	for _, r := range reports {
+		if r.Summary.Passed || r.Error == nil || r.Error.Type != "service_error" {
+			t.Errorf("Expected %s to fail with a service error, got %+v", r.File, r.Error)
+		}
+	}
+	if !strings.Contains(reports[5].Rules[0].Message, client.ErrCircuitOpen.Error()) {
+		t.Errorf("Expected the last file to meet the open breaker, got %q", reports[5].Rules[0].Message)
+	}
+	if code := exitCode(reports, "none"); code != exitService {
+		t.Errorf("exitCode() = %d, want %d", code, exitService)
+	}
+
+	for _, args := range [][]string{{"-api-retries", "-1"}, {"-api-timeout", "0s"}, {"-api-breaker", "-2"}} {
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		lf := registerLanguageFlags(flags)
+		if err := flags.Parse(args); err != nil {
+			t.Fatalf("Failed to parse flags: %v", err)
+		}
+		if _, err := lf.validator(context.Background()); err == nil {
+			t.Errorf("Expected an error for %v", args)
+		}
+	}
+}
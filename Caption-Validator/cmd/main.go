@@ -1,16 +1,17 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
 
-	"caption-validator/internal/client"
 	"caption-validator/internal/parser"
-	"caption-validator/internal/validator"
+	"caption-validator/internal/report"
 )
 
 func main() {
@@ -19,114 +20,130 @@ func main() {
 	f, err := os.OpenFile(logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening log file: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitIO)
 	}
 	defer f.Close()
 	log.SetOutput(f)
 
+	// Subcommands are dispatched before the validation flags are parsed
+	if len(os.Args) > 1 {
+		subcommands := map[string]func([]string) int{
+			"convert": runConvert,
+			"batch":   runBatch,
+		}
+		if run, ok := subcommands[os.Args[1]]; ok {
+			code := run(os.Args[2:])
+			f.Close()
+			os.Exit(code)
+		}
+	}
+
 	// Parse command line flags
-	minCoverage := flag.Float64("coverage", 95.0, "Minimum percentage of time that should be covered by captions")
 	tStart := flag.String("t_start", "0", "Start time in seconds or HH:MM:SS format")
 	tEnd := flag.String("t_end", "", "End time in seconds or HH:MM:SS format (required)")
-	apiURL := flag.String("api", "http://localhost:8080/validate", "URL of the language validation API")
+	language := registerLanguageFlags(flag.CommandLine)
+	rules := registerRuleFlags(flag.CommandLine)
+	outputFormat := registerFormatFlag(flag.CommandLine)
+	failOn := registerFailOnFlag(flag.CommandLine)
+	inputFormat := flag.String("input-format", "", "Parse the captions as vtt, srt, ttml or scc instead of detecting the format, e.g. when reading from stdin (default: detect)")
 	flag.Parse()
 
-	// Ensure we have a captions file path as the last argument
+	if err := checkOutputFormat(*outputFormat); err != nil {
+		log.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if err := checkFailOn(*failOn); err != nil {
+		log.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if *inputFormat != "" {
+		format, ok := parser.LookupFormat(*inputFormat)
+		if !ok {
+			log.Printf("Error: Unknown input format: %s\n", *inputFormat)
+			os.Exit(exitUsage)
+		}
+		*inputFormat = format
+	}
+
+	// Ensure we have a captions file path as the last argument; "-" reads stdin
 	args := flag.Args()
 	if len(args) != 1 {
 		log.Println("Error: Missing captions file path")
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 	captionsPath := args[0]
 
-	// Check if file exists
-	if _, err := os.Stat(captionsPath); os.IsNotExist(err) {
-		log.Printf("Error: Captions file does not exist: %s\n", captionsPath)
-		// Print error to stdout in the same format as other errors
-		fmt.Printf("{\"type\": \"file_not_found\", \"file\": \"%s\", \"error\": \"Caption file not found\"}\n", captionsPath)
-		os.Exit(1)
-	}
-
 	// Parse start and end times to seconds
 	startSec, err := parseTimeInput(*tStart)
 	if err != nil {
 		log.Printf("Error parsing t_start: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
 	// End time is required
 	if *tEnd == "" {
 		log.Println("Error: t_end is required")
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
 	endSec, err := parseTimeInput(*tEnd)
 	if err != nil {
 		log.Printf("Error parsing t_end: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
-	// Detect and parse captions file
-	captions, format, err := parser.ParseCaptionsFile(captionsPath)
+	profile, err := rules.profile(flag.CommandLine, language.ruleParams(flag.CommandLine))
 	if err != nil {
-		if err == parser.ErrUnsupportedFormat {
-			log.Printf("Error: Unsupported caption format for file: %s\n", captionsPath)
-			// Print error to stdout to differentiate from success cases
-			fmt.Printf("{\"type\": \"unsupported_format\", \"file\": \"%s\", \"error\": \"Unsupported caption file format\"}\n", captionsPath)
-			os.Exit(1)
-		}
-		log.Printf("Error parsing captions file: %v\n", err)
-		os.Exit(1)
+		log.Printf("Error loading profile: %v\n", err)
+		os.Exit(exitUsage)
 	}
 
-	log.Printf("Detected caption format: %s\n", format)
-	log.Printf("Validating captions from %s to %s with minimum coverage of %.2f%%\n", 
-		formatSeconds(startSec), formatSeconds(endSec), *minCoverage)
-
-	// Get the plain text content from captions
-	plainText := parser.ExtractPlainText(captions)
-
-	// Perform validations
-	hasFailures := false
-
-	// Validate caption coverage
-	coverageResult, err := validator.ValidateCoverage(captions, startSec, endSec, *minCoverage)
+	// Ctrl-C stops waiting on the language API, retries included
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	validateLanguage, err := language.validator(ctx)
 	if err != nil {
-		log.Printf("Error validating coverage: %v\n", err)
-		os.Exit(1)
+		log.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	opts := fileOptions{
+		startSec:         startSec,
+		endSec:           endSec,
+		profile:          profile,
+		inputFormat:      *inputFormat,
+		validateLanguage: validateLanguage,
+	}
+	if err := language.apply(&opts); err != nil {
+		log.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	log.Printf("Validating captions from %s to %s with profile %q\n",
+		formatSeconds(startSec), formatSeconds(endSec), profile.Name)
+
+	// Run every rule of the profile and report the result, pass or fail
+	rep := validateFile(captionsPath, opts)
+	if *outputFormat == "json" {
+		err = rep.WriteJSON(os.Stdout)
+	} else {
+		err = writeReports(os.Stdout, *outputFormat, []*report.Report{rep})
 	}
-	
-	if !coverageResult.Valid {
-		fmt.Printf("%s\n", coverageResult.JSON())
-		hasFailures = true
+	if err != nil {
+		log.Printf("Error writing report: %v\n", err)
+		os.Exit(exitIO)
 	}
 
-	// Validate language via API if URL is provided
-	if *apiURL != "" {
-		log.Printf("Validating language using API: %s", *apiURL)
-		langResult, err := client.ValidateLanguage(*apiURL, plainText)
-		if err != nil {
-			log.Printf("Error validating language: %v\n", err)
-			log.Println("Skipping language validation")
-		} else {
-			log.Printf("Language validation result: detected='%s', expected='%s', valid=%v", 
-				langResult.Language, langResult.ExpectedLang, langResult.Valid)
-			if !langResult.Valid {
-				fmt.Printf("%s\n", langResult.JSON())
-				log.Println("Validation failed: Non-English language detected")
-				os.Exit(1)
-			}
-		}
+	if rep.Summary.Passed {
+		log.Println("Validation completed successfully")
 	} else {
-		log.Println("Language validation skipped (no API URL provided)")
-	}
-
-	// Exit with code 0 regardless of validation failures
-	if hasFailures {
 		log.Println("Validation completed with failures")
-	} else {
-		log.Println("Validation completed successfully")
 	}
+
+	// The report decides the exit code: broken input, a failed service or
+	// findings at the -fail-on severity
+	code := exitCode([]*report.Report{rep}, *failOn)
+	log.Printf("Exiting with code %d\n", code)
+	f.Close()
+	os.Exit(code)
 }
 
 // parseTimeInput converts a time string (either seconds or HH:MM:SS format) to seconds
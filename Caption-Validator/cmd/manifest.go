@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"caption-validator/internal/client"
+)
+
+// manifestEntry holds the validation settings for one caption file in a batch manifest.
+// Empty fields fall back to the batch command line flags.
+type manifestEntry struct {
+	File     string       `json:"file"`
+	TStart   manifestTime `json:"t_start"`
+	TEnd     manifestTime `json:"t_end"`
+	Coverage *float64     `json:"coverage"`
+	MaxGap   *float64     `json:"max_gap"`
+	Language string       `json:"language"`
+}
+
+// errInvalidManifest is wrapped by every error about a manifest's content, as
+// opposed to errors reading it
+var errInvalidManifest = errors.New("invalid manifest")
+
+// manifestTime accepts either a JSON number of seconds or any string accepted by parseTimeInput
+type manifestTime string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (mt *manifestTime) UnmarshalJSON(data []byte) error {
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err == nil {
+		*mt = manifestTime(number.String())
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("time must be a number of seconds or a string: %s", data)
+	}
+	*mt = manifestTime(text)
+	return nil
+}
+
+// loadManifest reads a batch manifest. Files ending in .csv are read as CSV
+// with a header row naming the columns (file, t_start, t_end, coverage,
+// max_gap, language); anything else is read as a JSON array of entries.
+func loadManifest(path string) ([]manifestEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []manifestEntry
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		entries, err = parseCSVManifest(string(data))
+	} else {
+		err = json.Unmarshal(data, &entries)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", errInvalidManifest, path, err)
+	}
+
+	for i, entry := range entries {
+		if strings.TrimSpace(entry.File) == "" {
+			return nil, fmt.Errorf("%w %s: entry %d has no file", errInvalidManifest, path, i+1)
+		}
+	}
+
+	return entries, nil
+}
+
+// parseCSVManifest parses the CSV form of a manifest
+func parseCSVManifest(data string) ([]manifestEntry, error) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	// Map column names to positions so columns may appear in any order
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["file"]; !ok {
+		return nil, fmt.Errorf("missing \"file\" column in CSV header")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var entries []manifestEntry
+	for line, record := range records[1:] {
+		entry := manifestEntry{
+			File:     field(record, "file"),
+			TStart:   manifestTime(field(record, "t_start")),
+			TEnd:     manifestTime(field(record, "t_end")),
+			Language: field(record, "language"),
+		}
+		if coverage := field(record, "coverage"); coverage != "" {
+			value, err := strconv.ParseFloat(coverage, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid coverage %q", line+2, coverage)
+			}
+			entry.Coverage = &value
+		}
+		if maxGap := field(record, "max_gap"); maxGap != "" {
+			value, err := strconv.ParseFloat(maxGap, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid max_gap %q", line+2, maxGap)
+			}
+			entry.MaxGap = &value
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// resolve applies the entry's overrides to the batch defaults and returns
+// the path of the caption file, relative to the manifest's directory
+func (entry manifestEntry) resolve(manifestDir string, defaults fileOptions) (string, fileOptions, error) {
+	path := entry.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(manifestDir, path)
+	}
+
+	opts := defaults
+	var err error
+	if entry.TStart != "" {
+		if opts.startSec, err = parseTimeInput(string(entry.TStart)); err != nil {
+			return "", opts, fmt.Errorf("invalid t_start for %s: %w", entry.File, err)
+		}
+	}
+	if entry.TEnd != "" {
+		if opts.endSec, err = parseTimeInput(string(entry.TEnd)); err != nil {
+			return "", opts, fmt.Errorf("invalid t_end for %s: %w", entry.File, err)
+		}
+	}
+	if entry.Coverage != nil {
+		opts.profile = opts.profile.WithParam("coverage", "min_coverage", *entry.Coverage)
+	}
+	if entry.MaxGap != nil {
+		opts.profile = opts.profile.WithParam("max_gap", "max_gap", *entry.MaxGap)
+	}
+	if entry.Language != "" {
+		tag, err := client.ParseLanguageTag(entry.Language)
+		if err != nil {
+			return "", opts, fmt.Errorf("invalid language for %s: %w", entry.File, err)
+		}
+		opts.expectedLang = tag.String()
+	}
+
+	return path, opts, nil
+}
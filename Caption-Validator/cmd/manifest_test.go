@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"caption-validator/internal/validator"
+)
+
+func TestLoadManifest(t *testing.T) {
+	root := t.TempDir()
+
+	jsonPath := filepath.Join(root, "manifest.json")
+	jsonManifest := `[
+		{"file": "episode1.vtt", "t_start": 5, "t_end": "00:01:00", "coverage": 80, "language": "fr-FR"},
+		{"file": "episode2.srt"}
+	]`
+	if err := os.WriteFile(jsonPath, []byte(jsonManifest), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	csvPath := filepath.Join(root, "manifest.csv")
+	csvManifest := "language,file,coverage,t_start,t_end\nfr-FR,episode1.vtt,80,5,00:01:00\n,episode2.srt,,,\n"
+	if err := os.WriteFile(csvPath, []byte(csvManifest), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	defaults := fileOptions{profile: validator.Profile{Rules: []validator.RuleConfig{
+		{Rule: "coverage", Params: validator.Params{"min_coverage": 95.0}},
+	}}}
+
+	for _, path := range []string{jsonPath, csvPath} {
+		t.Run(filepath.Ext(path), func(t *testing.T) {
+			entries, err := loadManifest(path)
+			if err != nil {
+				t.Fatalf("loadManifest returned error: %v", err)
+			}
+			if len(entries) != 2 {
+				t.Fatalf("Expected 2 entries, got %d", len(entries))
+			}
+
+			file, opts, err := entries[0].resolve(root, defaults)
+			if err != nil {
+				t.Fatalf("resolve returned error: %v", err)
+			}
+			if file != filepath.Join(root, "episode1.vtt") {
+				t.Errorf("Expected path relative to manifest, got %s", file)
+			}
+			if opts.startSec != 5 || opts.endSec != 60 || opts.expectedLang != "fr-FR" {
+				t.Errorf("Unexpected options for episode1: %+v", opts)
+			}
+			if coverage := opts.profile.Rules[0].Params["min_coverage"]; coverage != 80.0 {
+				t.Errorf("Expected manifest coverage 80, got %v", coverage)
+			}
+
+			_, opts, err = entries[1].resolve(root, defaults)
+			if err != nil {
+				t.Fatalf("resolve returned error: %v", err)
+			}
+			if coverage := opts.profile.Rules[0].Params["min_coverage"]; coverage != 95.0 || opts.expectedLang != "" {
+				t.Errorf("Expected defaults for episode2, got %+v", opts)
+			}
+		})
+	}
+}
+
+func TestLoadManifestInvalid(t *testing.T) {
+	root := t.TempDir()
+	manifests := map[string]string{
+		"nofile.json":  `[{"coverage": 90}]`,
+		"badtime.json": `[{"file": "a.vtt", "t_start": true}]`,
+		"nocol.csv":    "name,coverage\na.vtt,90\n",
+	}
+	for name, content := range manifests {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write manifest: %v", err)
+		}
+		if _, err := loadManifest(path); !errors.Is(err, errInvalidManifest) {
+			t.Errorf("Expected invalid manifest error loading %s, got %v", name, err)
+		}
+	}
+}
+
+func TestRunManifestBatch(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"episode1.vtt": "WEBVTT\n\n1\n00:00:00.000 --> 00:00:30.000\nHello there\n",
+		"episode2.srt": "1\n00:00:00,000 --> 00:00:10,000\nHello there\n",
+		"extra.vtt":    "WEBVTT\n\n1\n00:00:00.000 --> 00:00:10.000\nNot in the manifest\n",
+		// Not caption files, so neither validated nor unlisted
+		"README":                "Season one captions",
+		"notes.txt":             "Episode 3 is late",
+		"caption-validator.log": "Validating episode1.vtt",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	// episode1 only passes with its own window; episode2 fails the default coverage
+	manifest := `[
+		{"file": "episode1.vtt", "t_end": 30},
+		{"file": "episode2.srt"},
+		{"file": "episode3.vtt"}
+	]`
+	manifestPath := filepath.Join(root, "manifest.json")
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	defaults := fileOptions{endSec: 60, profile: validator.Profile{Rules: []validator.RuleConfig{
+		{Rule: "coverage", Params: validator.Params{"min_coverage": 95.0}},
+	}}}
+	include, err := parseIncludePatterns(defaultBatchInclude)
+	if err != nil {
+		t.Fatalf("parseIncludePatterns returned error: %v", err)
+	}
+	results, err := runManifestBatch(manifestPath, nil, include, defaults, 2)
+	if err != nil {
+		t.Fatalf("runManifestBatch returned error: %v", err)
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d: %+v", len(results), results)
+	}
+	if r := results[0]; filepath.Base(r.File) != "episode1.vtt" || !r.Summary.Passed {
+		t.Errorf("episode1.vtt should pass with its manifest window, got %+v", r)
+	}
+	if r := results[1]; filepath.Base(r.File) != "episode2.srt" || r.Summary.Passed || r.Error != nil {
+		t.Errorf("episode2.srt should fail coverage, got %+v", r)
+	}
+	if r := results[2]; filepath.Base(r.File) != "episode3.vtt" || r.Error == nil || r.Error.Type != "missing_file" {
+		t.Errorf("episode3.vtt should be reported missing, got %+v", r)
+	}
+	if r := results[3]; filepath.Base(r.File) != "extra.vtt" || r.Error == nil || r.Error.Type != "unlisted_file" {
+		t.Errorf("extra.vtt should be reported unlisted, got %+v", r)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"caption-validator/internal/report"
+)
+
+// outputFormats are the values accepted by -format. json is the report (or
+// batch document) itself; the others render the same reports for other tools.
+var outputFormats = []string{"json", "sarif", "junit", "html"}
+
+// registerFormatFlag defines the -format flag on a flag set
+func registerFormatFlag(flags *flag.FlagSet) *string {
+	return flags.String("format", "json", "Output format: "+strings.Join(outputFormats, ", "))
+}
+
+// checkOutputFormat rejects unknown -format values before any file is read
+func checkOutputFormat(format string) error {
+	for _, name := range outputFormats {
+		if format == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %q (want one of %s)", format, strings.Join(outputFormats, ", "))
+}
+
+// writeReports renders reports in one of the non-JSON output formats
+func writeReports(w io.Writer, format string, reports []*report.Report) error {
+	switch format {
+	case "sarif":
+		return report.WriteSARIF(w, reports)
+	case "junit":
+		return report.WriteJUnit(w, reports)
+	case "html":
+		return report.WriteHTML(w, reports)
+	default:
+		return checkOutputFormat(format)
+	}
+}
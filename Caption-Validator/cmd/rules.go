@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+	"strings"
+
+	"caption-validator/internal/validator"
+)
+
+// ruleFlags are the rule thresholds shared by single-file and batch validation.
+// They describe a profile; -profile replaces them with one read from a file.
+type ruleFlags struct {
+	profilePath     *string
+	minCoverage     *float64
+	maxGap          *float64
+	maxCPS          *float64
+	maxWPM          *float64
+	maxLineLength   *int
+	maxLines        *int
+	minDuration     *float64
+	maxDuration     *float64
+	minCueGapFrames *float64
+	frameRate       *float64
+}
+
+// ruleFlagNames are the flags ignored when a profile file is given, the
+// language rule flags of languageFlags included
+var ruleFlagNames = map[string]bool{
+	"coverage": true, "max_gap": true, "max_cps": true, "max_wpm": true,
+	"max_line_length": true, "max_lines": true, "min_duration": true,
+	"max_duration": true, "min_cue_gap_frames": true, "frame_rate": true,
+	"lang-mode": true, "lang-window": true, "lang-allow": true,
+}
+
+// registerRuleFlags defines the rule flags on a flag set
+func registerRuleFlags(flags *flag.FlagSet) *ruleFlags {
+	return &ruleFlags{
+		profilePath:     flags.String("profile", "", "Built-in profile ("+strings.Join(validator.BuiltinProfileNames(), ", ")+") or YAML/JSON profile file (replaces the threshold flags)"),
+		minCoverage:     flags.Float64("coverage", 95.0, "Minimum percentage of time that should be covered by captions"),
+		maxGap:          flags.Float64("max_gap", 0, "Fail if any single uncaptioned interval is longer than this many seconds (0 disables)"),
+		maxCPS:          flags.Float64("max_cps", 0, "Fail captions faster than this many characters per second (0 disables)"),
+		maxWPM:          flags.Float64("max_wpm", 0, "Fail captions faster than this many words per minute (0 disables)"),
+		maxLineLength:   flags.Int("max_line_length", 0, "Fail caption lines longer than this many characters (0: 32 for SCC, otherwise no limit)"),
+		maxLines:        flags.Int("max_lines", 0, "Fail captions with more than this many lines (0: 4 for SCC, otherwise no limit)"),
+		minDuration:     flags.Float64("min_duration", 0, "Fail captions shown for less than this many seconds (0 disables)"),
+		maxDuration:     flags.Float64("max_duration", 0, "Fail captions shown for more than this many seconds (0 disables)"),
+		minCueGapFrames: flags.Float64("min_cue_gap_frames", 0, "Fail consecutive captions separated by fewer than this many frames (0 disables)"),
+		frameRate:       flags.Float64("frame_rate", 29.97, "Frame rate used by -min_cue_gap_frames"),
+	}
+}
+
+// profile returns the profile to validate with: the -profile file if given,
+// otherwise one built from the threshold flags and the language rule's
+// params. Every failure of a flag-built profile is an error.
+func (rf *ruleFlags) profile(flags *flag.FlagSet, language validator.Params) (validator.Profile, error) {
+	if *rf.profilePath != "" {
+		flags.Visit(func(f *flag.Flag) {
+			if ruleFlagNames[f.Name] {
+				log.Printf("Warning: -%s is ignored when -profile is given\n", f.Name)
+			}
+		})
+		return validator.ResolveProfile(*rf.profilePath)
+	}
+
+	profile := validator.Profile{Name: "flags"}
+	add := func(rule string, params validator.Params) {
+		profile.Rules = append(profile.Rules, validator.RuleConfig{Rule: rule, Params: params})
+	}
+
+	add("coverage", validator.Params{"min_coverage": *rf.minCoverage})
+	if *rf.maxGap > 0 {
+		add("max_gap", validator.Params{"max_gap": *rf.maxGap})
+	}
+	if *rf.maxCPS > 0 || *rf.maxWPM > 0 {
+		add("reading_speed", validator.Params{"max_cps": *rf.maxCPS, "max_wpm": *rf.maxWPM})
+	}
+	add("cue_timing", validator.Params{
+		"min_duration":   *rf.minDuration,
+		"max_duration":   *rf.maxDuration,
+		"min_gap_frames": *rf.minCueGapFrames,
+		"frame_rate":     *rf.frameRate,
+	})
+	add("cue_ordering", nil)
+	// Unset line limits default to the format's, so SCC is held to CEA-608
+	add("line_length", validator.Params{"max_chars_per_line": *rf.maxLineLength, "max_lines": *rf.maxLines})
+	add("language", language)
+
+	// Surface bad thresholds (e.g. a negative frame rate) before any file is read
+	if _, err := validator.NewEngine(profile); err != nil {
+		return validator.Profile{}, err
+	}
+	return profile, nil
+}
+
+// checkCaptions runs every rule of the engine, logging skipped and failed
+// rules, and returns the outcomes in profile order
+func checkCaptions(engine *validator.Engine, ctx validator.Context) []validator.Outcome {
+	outcomes := engine.Run(ctx)
+	for _, outcome := range outcomes {
+		switch {
+		case errors.Is(outcome.Err, validator.ErrNotApplicable):
+		case errors.Is(outcome.Err, validator.ErrSkipped):
+			log.Printf("Skipping %s rule: %v\n", outcome.Rule, outcome.Err)
+		case outcome.Err != nil:
+			log.Printf("Rule %s could not run: %v\n", outcome.Rule, outcome.Err)
+		case !outcome.Result.Valid:
+			log.Printf("Rule %s failed (%s)\n", outcome.Rule, outcome.Severity)
+		}
+	}
+	return outcomes
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"caption-validator/internal/client"
+	"caption-validator/internal/parser"
+	"caption-validator/internal/report"
+	"caption-validator/internal/validator"
+)
+
+// stdinPath is the file argument that reads captions from standard input
+const stdinPath = "-"
+
+// fileOptions holds the settings a caption file is validated with
+type fileOptions struct {
+	startSec     float64
+	endSec       float64 // 0 means "use the end of the last caption in the file"
+	profile      validator.Profile
+	expectedLang string // overrides the language rule when set
+	// languageMatch overrides the language rule's matching mode when set
+	languageMatch client.LanguageMatch
+	// inferLanguage takes the expected language from the file name, e.g.
+	// episode1.fr.vtt, when expectedLang is not set
+	inferLanguage bool
+	inputFormat   string // parse as this format instead of detecting it when set
+	// strictLanguage fails the file when the language API is unavailable,
+	// instead of skipping the language rule
+	strictLanguage bool
+	// validateLanguage is shared by all files so API connections are reused
+	validateLanguage func(string, string) (client.LanguageValidationResult, error)
+}
+
+// validateFile parses one caption file and runs every rule of the profile
+// on it. Problems with the file itself are recorded in the report rather
+// than returned, so every file gets a report.
+func validateFile(path string, opts fileOptions) *report.Report {
+	started := time.Now()
+	if opts.expectedLang == "" && opts.inferLanguage && path != stdinPath {
+		if lang, ok := client.LanguageFromFilename(path); ok {
+			log.Printf("Expecting %s captions in %s from its file name\n", lang, path)
+			opts.expectedLang = lang
+		}
+	}
+	rep := report.New(path, report.Params{
+		StartTime:        opts.startSec,
+		EndTime:          opts.endSec,
+		ExpectedLanguage: opts.expectedLang,
+		Profile:          opts.profile,
+	})
+	defer func() {
+		rep.Timings.TotalMS = report.Millis(time.Since(started))
+	}()
+
+	if _, err := os.Stat(path); path != stdinPath && os.IsNotExist(err) {
+		log.Printf("Error: Captions file does not exist: %s\n", path)
+		rep.Fail("file_not_found", "Caption file not found")
+		return rep
+	}
+
+	captions, detection, err := parseInput(path, opts.inputFormat)
+	rep.Timings.ParseMS = report.Millis(time.Since(started))
+	switch {
+	case errors.Is(err, parser.ErrUnsupportedFormat):
+		log.Printf("Error: Unsupported caption format for file: %s (%v; candidates: %s)\n", path, err, detection)
+		rep.Fail("unsupported_format", "Unsupported caption file format")
+		return rep
+	case errors.Is(err, parser.ErrAmbiguousFormat):
+		log.Printf("Error: Ambiguous caption format for file: %s (%s)\n", path, detection)
+		rep.Fail("ambiguous_format", fmt.Sprintf("Caption format is ambiguous: %s; set -input-format", detection))
+		return rep
+	case err != nil:
+		log.Printf("Error parsing %s: %v\n", path, err)
+		rep.Fail("parse_error", err.Error())
+		return rep
+	}
+	format := detection.Format
+	rep.Format = format
+	rep.FormatConfidence = detection.Confidence
+	rep.Captions = captions
+	log.Printf("Detected caption format of %s: %s\n", path, format)
+
+	endSec := opts.endSec
+	if endSec == 0 {
+		for _, caption := range captions {
+			if caption.EndTime > endSec {
+				endSec = caption.EndTime
+			}
+		}
+		rep.Params.EndTime = endSec
+	}
+
+	engine, err := validator.NewEngine(opts.profile)
+	if err != nil {
+		log.Printf("Error validating %s: %v\n", path, err)
+		rep.Fail("validation_error", err.Error())
+		return rep
+	}
+
+	validateStarted := time.Now()
+	rep.AddOutcomes(checkCaptions(engine, validator.Context{
+		Captions:         captions,
+		Format:           format,
+		StartTime:        opts.startSec,
+		EndTime:          endSec,
+		ExpectedLanguage: opts.expectedLang,
+		LanguageMatch:    opts.languageMatch,
+		ValidateLanguage: opts.validateLanguage,
+		Strict:           opts.strictLanguage,
+	}))
+	rep.Timings.ValidateMS = report.Millis(time.Since(validateStarted))
+
+	if rep.Error != nil {
+		log.Printf("Error validating %s: %s\n", path, rep.Error.Message)
+	}
+	return rep
+}
+
+// parseInput parses the captions at path, or on standard input for "-". The
+// format is sniffed from the content, with a file's extension as a hint,
+// unless inputFormat names one; the detection is returned either way, with
+// no confidence when the format was given.
+func parseInput(path string, inputFormat string) ([]parser.Caption, parser.Detection, error) {
+	var r io.Reader = os.Stdin
+	name := ""
+	if path != stdinPath {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, parser.Detection{}, err
+		}
+		defer file.Close()
+		r, name = file, path
+	}
+
+	if inputFormat != "" {
+		captions, err := parser.ParseCaptions(r, inputFormat)
+		return captions, parser.Detection{Format: inputFormat}, err
+	}
+
+	br := bufio.NewReader(r)
+	detection, err := parser.SniffReader(br, name)
+	if err != nil {
+		return nil, detection, err
+	}
+	format, err := detection.Result()
+	if err != nil {
+		return nil, detection, err
+	}
+	captions, err := parser.ParseCaptions(br, format)
+	return captions, detection, err
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"caption-validator/internal/parser"
+	"caption-validator/internal/validator"
+)
+
+func TestParseInput(t *testing.T) {
+	root := t.TempDir()
+	srt := "1\n00:00:00,000 --> 00:00:10,000\nHello there\n"
+
+	// TTML without a namespace, in a file whose name does not say so
+	path := filepath.Join(root, "captions.txt")
+	ttml := "<tt><body><div><p begin=\"0s\" end=\"10s\">Hello there</p></div></body></tt>\n"
+	if err := os.WriteFile(path, []byte(ttml), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if _, _, err := parseInput(path, ""); !errors.Is(err, parser.ErrUnsupportedFormat) {
+		t.Errorf("Expected detection to fail, got %v", err)
+	}
+	captions, detection, err := parseInput(path, parser.FormatTTML)
+	if err != nil || detection.Format != parser.FormatTTML || detection.Confidence != 0 || len(captions) != 1 {
+		t.Errorf("parseInput() with -input-format = %+v, %+v, %v", captions, detection, err)
+	}
+
+	// Content that fits two formats equally well is flagged, not guessed
+	ambiguous := filepath.Join(root, "ambiguous.vtt")
+	if err := os.WriteFile(ambiguous, []byte("1\n00:00:01,000 --> 00:00:02,000\nWEBVTT is a format\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if rep := validateFile(ambiguous, fileOptions{endSec: 10}); rep.Error == nil || rep.Error.Type != "ambiguous_format" {
+		t.Errorf("Expected an ambiguous_format error, got %+v", rep)
+	}
+
+	// Standard input is sniffed from its content
+	stdinFile := filepath.Join(root, "stdin")
+	if err := os.WriteFile(stdinFile, []byte(srt), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	stdin, err := os.Open(stdinFile)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer stdin.Close()
+	saved := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = saved }()
+
+	rep := validateFile("-", fileOptions{endSec: 10, profile: validator.Profile{Rules: []validator.RuleConfig{
+		{Rule: "coverage", Params: validator.Params{"min_coverage": 95.0}},
+	}}})
+	if rep.Error != nil || rep.Format != parser.FormatSRT || rep.FormatConfidence != 0.9 || !rep.Summary.Passed {
+		t.Errorf("Expected stdin to validate as SRT, got %+v", rep)
+	}
+}
@@ -1,3 +1,5 @@
 module caption-validator
 
 go 1.23.1
+
+require gopkg.in/yaml.v3 v3.0.1
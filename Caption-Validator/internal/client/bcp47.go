@@ -0,0 +1,273 @@
+package client
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// LanguageTag is a parsed BCP 47 language tag such as "en-US" or
+// "zh-Hant-TW". The language, script and region subtags are interpreted;
+// any further subtags (variants, extensions) are kept as they are.
+type LanguageTag struct {
+	Language string // lower case, e.g. "en"
+	Script   string // title case, e.g. "Hant", or ""
+	Region   string // upper case, e.g. "US" or "419", or ""
+	Rest     []string
+}
+
+// ParseLanguageTag parses and canonicalises a BCP 47 tag. Underscores are
+// accepted as separators ("en_GB"), and three-letter ISO 639-2 codes with a
+// two-letter equivalent are shortened ("fra" and "fre" become "fr").
+func ParseLanguageTag(tag string) (LanguageTag, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return LanguageTag{}, fmt.Errorf("empty language tag")
+	}
+	subtags := strings.Split(strings.ReplaceAll(tag, "_", "-"), "-")
+	for _, subtag := range subtags {
+		if subtag == "" || len(subtag) > 8 || !isAlphanumeric(subtag) {
+			return LanguageTag{}, fmt.Errorf("invalid language tag %q", tag)
+		}
+	}
+
+	language := strings.ToLower(subtags[0])
+	if (len(language) != 2 && len(language) != 3) || !isLetters(language) {
+		return LanguageTag{}, fmt.Errorf("invalid language tag %q: the language must be a two or three letter code", tag)
+	}
+	if short, ok := iso6392Codes[language]; ok {
+		language = short
+	}
+
+	parsed := LanguageTag{Language: language}
+	subtags = subtags[1:]
+	if len(subtags) > 0 && len(subtags[0]) == 4 && isLetters(subtags[0]) {
+		parsed.Script = strings.ToUpper(subtags[0][:1]) + strings.ToLower(subtags[0][1:])
+		subtags = subtags[1:]
+	}
+	if len(subtags) > 0 && ((len(subtags[0]) == 2 && isLetters(subtags[0])) || (len(subtags[0]) == 3 && isDigits(subtags[0]))) {
+		parsed.Region = strings.ToUpper(subtags[0])
+		subtags = subtags[1:]
+	}
+	for _, subtag := range subtags {
+		parsed.Rest = append(parsed.Rest, strings.ToLower(subtag))
+	}
+	return parsed, nil
+}
+
+// CanonicalLanguageTag returns tag in canonical form, or tag unchanged if it
+// is not a valid BCP 47 tag
+func CanonicalLanguageTag(tag string) string {
+	parsed, err := ParseLanguageTag(tag)
+	if err != nil {
+		return tag
+	}
+	return parsed.String()
+}
+
+// String formats the tag, e.g. "en-US"
+func (t LanguageTag) String() string {
+	return strings.Join(t.subtags(), "-")
+}
+
+// subtags lists the tag's subtags in order
+func (t LanguageTag) subtags() []string {
+	subtags := []string{t.Language}
+	if t.Script != "" {
+		subtags = append(subtags, t.Script)
+	}
+	if t.Region != "" {
+		subtags = append(subtags, t.Region)
+	}
+	return append(subtags, t.Rest...)
+}
+
+// LanguageMatch says how closely a detected language must match the
+// expected one
+type LanguageMatch string
+
+const (
+	// MatchExact accepts only the expected tag itself: en-US accepts en-US
+	MatchExact LanguageMatch = "exact"
+	// MatchPrefix accepts tags that are a prefix of each other, subtag by
+	// subtag: en accepts en-US and en-GB, and en-US accepts a bare en
+	MatchPrefix LanguageMatch = "prefix"
+	// MatchLanguage accepts any tag with the same language: en-US accepts
+	// en-GB and en
+	MatchLanguage LanguageMatch = "language"
+)
+
+// ParseLanguageMatch validates a matching mode name. An empty name means
+// MatchExact.
+func ParseLanguageMatch(name string) (LanguageMatch, error) {
+	switch LanguageMatch(strings.ToLower(name)) {
+	case "", MatchExact:
+		return MatchExact, nil
+	case MatchPrefix:
+		return MatchPrefix, nil
+	case MatchLanguage:
+		return MatchLanguage, nil
+	}
+	return "", fmt.Errorf("unknown language match %q (want exact, prefix or language)", name)
+}
+
+// Matches reports whether a detected language satisfies the expected one.
+// Tags are compared in canonical form, so case and separators do not matter.
+func (m LanguageMatch) Matches(detected string, expected string) bool {
+	got, err := ParseLanguageTag(detected)
+	if err != nil {
+		return false
+	}
+	want, err := ParseLanguageTag(expected)
+	if err != nil {
+		return false
+	}
+
+	switch m {
+	case MatchLanguage:
+		return got.Language == want.Language
+	case MatchPrefix:
+		a, b := got.subtags(), want.subtags()
+		if len(a) > len(b) {
+			a, b = b, a
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	default:
+		return got.String() == want.String()
+	}
+}
+
+// LanguageName describes a tag in English for messages, e.g. "English (US)"
+// for en-US, falling back to the tag itself for unknown languages
+func LanguageName(tag string) string {
+	parsed, err := ParseLanguageTag(tag)
+	if err != nil {
+		return tag
+	}
+	name, ok := languageNames[parsed.Language]
+	if !ok {
+		return parsed.String()
+	}
+
+	var qualifiers []string
+	if parsed.Script != "" {
+		qualifiers = append(qualifiers, parsed.Script)
+	}
+	if parsed.Region != "" {
+		qualifiers = append(qualifiers, parsed.Region)
+	}
+	if len(qualifiers) > 0 {
+		name += " (" + strings.Join(qualifiers, ", ") + ")"
+	}
+	return name
+}
+
+// LanguageFromFilename infers the language of a caption file from the
+// conventional naming scheme name.<language>.<ext>, e.g. episode1.fr.vtt,
+// episode1.pt-BR.srt or episode1.en.sdh.vtt. Only known language codes are
+// recognised, so names like episode1.final.vtt are not mistaken for one.
+func LanguageFromFilename(path string) (string, bool) {
+	parts := strings.Split(filepath.Base(path), ".")
+	// The first part is the name and the last the extension
+	for i := len(parts) - 2; i >= 1; i-- {
+		tag, err := ParseLanguageTag(parts[i])
+		if err != nil {
+			continue
+		}
+		if _, known := languageNames[tag.Language]; known {
+			return tag.String(), true
+		}
+	}
+	return "", false
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if (r < '0' || r > '9') && !isLetters(string(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// iso6392Codes maps the three-letter ISO 639-2 codes (bibliographic and
+// terminology) commonly found in caption file names to their two-letter
+// ISO 639-1 equivalents, which BCP 47 prefers
+var iso6392Codes = map[string]string{
+	"ara": "ar", "ben": "bn", "bul": "bg", "cat": "ca", "ces": "cs", "chi": "zh",
+	"cze": "cs", "dan": "da", "deu": "de", "dut": "nl", "ell": "el", "eng": "en",
+	"est": "et", "fas": "fa", "fin": "fi", "fra": "fr", "fre": "fr", "ger": "de",
+	"gre": "el", "heb": "he", "hin": "hi", "hrv": "hr", "hun": "hu", "ind": "id",
+	"isl": "is", "ice": "is", "ita": "it", "jpn": "ja", "kor": "ko", "lav": "lv",
+	"lit": "lt", "may": "ms", "msa": "ms", "nld": "nl", "nor": "no", "per": "fa",
+	"pol": "pl", "por": "pt", "ron": "ro", "rum": "ro", "rus": "ru", "slk": "sk",
+	"slo": "sk", "slv": "sl", "spa": "es", "srp": "sr", "swa": "sw", "swe": "sv",
+	"tam": "ta", "tel": "te", "tha": "th", "tur": "tr", "ukr": "uk", "urd": "ur",
+	"vie": "vi", "zho": "zh",
+}
+
+// languageNames are the English names of the ISO 639-1 languages, plus a few
+// three-letter languages without a two-letter code that subtitles use
+var languageNames = map[string]string{
+	"aa": "Afar", "ab": "Abkhazian", "ae": "Avestan", "af": "Afrikaans", "ak": "Akan",
+	"am": "Amharic", "an": "Aragonese", "ar": "Arabic", "as": "Assamese", "av": "Avaric",
+	"ay": "Aymara", "az": "Azerbaijani", "ba": "Bashkir", "be": "Belarusian", "bg": "Bulgarian",
+	"bi": "Bislama", "bm": "Bambara", "bn": "Bengali", "bo": "Tibetan", "br": "Breton",
+	"bs": "Bosnian", "ca": "Catalan", "ce": "Chechen", "ch": "Chamorro", "co": "Corsican",
+	"cr": "Cree", "cs": "Czech", "cu": "Church Slavic", "cv": "Chuvash", "cy": "Welsh",
+	"da": "Danish", "de": "German", "dv": "Divehi", "dz": "Dzongkha", "ee": "Ewe",
+	"el": "Greek", "en": "English", "eo": "Esperanto", "es": "Spanish", "et": "Estonian",
+	"eu": "Basque", "fa": "Persian", "ff": "Fulah", "fi": "Finnish", "fj": "Fijian",
+	"fo": "Faroese", "fr": "French", "fy": "Western Frisian", "ga": "Irish", "gd": "Scottish Gaelic",
+	"gl": "Galician", "gn": "Guarani", "gu": "Gujarati", "gv": "Manx", "ha": "Hausa",
+	"he": "Hebrew", "hi": "Hindi", "ho": "Hiri Motu", "hr": "Croatian", "ht": "Haitian",
+	"hu": "Hungarian", "hy": "Armenian", "hz": "Herero", "ia": "Interlingua", "id": "Indonesian",
+	"ie": "Interlingue", "ig": "Igbo", "ii": "Sichuan Yi", "ik": "Inupiaq", "io": "Ido",
+	"is": "Icelandic", "it": "Italian", "iu": "Inuktitut", "ja": "Japanese", "jv": "Javanese",
+	"ka": "Georgian", "kg": "Kongo", "ki": "Kikuyu", "kj": "Kuanyama", "kk": "Kazakh",
+	"kl": "Kalaallisut", "km": "Khmer", "kn": "Kannada", "ko": "Korean", "kr": "Kanuri",
+	"ks": "Kashmiri", "ku": "Kurdish", "kv": "Komi", "kw": "Cornish", "ky": "Kyrgyz",
+	"la": "Latin", "lb": "Luxembourgish", "lg": "Ganda", "li": "Limburgish", "ln": "Lingala",
+	"lo": "Lao", "lt": "Lithuanian", "lu": "Luba-Katanga", "lv": "Latvian", "mg": "Malagasy",
+	"mh": "Marshallese", "mi": "Maori", "mk": "Macedonian", "ml": "Malayalam", "mn": "Mongolian",
+	"mr": "Marathi", "ms": "Malay", "mt": "Maltese", "my": "Burmese", "na": "Nauru",
+	"nb": "Norwegian Bokmål", "nd": "North Ndebele", "ne": "Nepali", "ng": "Ndonga", "nl": "Dutch",
+	"nn": "Norwegian Nynorsk", "no": "Norwegian", "nr": "South Ndebele", "nv": "Navajo", "ny": "Chichewa",
+	"oc": "Occitan", "oj": "Ojibwa", "om": "Oromo", "or": "Odia", "os": "Ossetian",
+	"pa": "Punjabi", "pi": "Pali", "pl": "Polish", "ps": "Pashto", "pt": "Portuguese",
+	"qu": "Quechua", "rm": "Romansh", "rn": "Rundi", "ro": "Romanian", "ru": "Russian",
+	"rw": "Kinyarwanda", "sa": "Sanskrit", "sc": "Sardinian", "sd": "Sindhi", "se": "Northern Sami",
+	"sg": "Sango", "si": "Sinhala", "sk": "Slovak", "sl": "Slovenian", "sm": "Samoan",
+	"sn": "Shona", "so": "Somali", "sq": "Albanian", "sr": "Serbian", "ss": "Swati",
+	"st": "Southern Sotho", "su": "Sundanese", "sv": "Swedish", "sw": "Swahili", "ta": "Tamil",
+	"te": "Telugu", "tg": "Tajik", "th": "Thai", "ti": "Tigrinya", "tk": "Turkmen",
+	"tl": "Tagalog", "tn": "Tswana", "to": "Tonga", "tr": "Turkish", "ts": "Tsonga",
+	"tt": "Tatar", "tw": "Twi", "ty": "Tahitian", "ug": "Uyghur", "uk": "Ukrainian",
+	"ur": "Urdu", "uz": "Uzbek", "ve": "Venda", "vi": "Vietnamese", "vo": "Volapük",
+	"wa": "Walloon", "wo": "Wolof", "xh": "Xhosa", "yi": "Yiddish", "yo": "Yoruba",
+	"za": "Zhuang", "zh": "Chinese", "zu": "Zulu",
+	"fil": "Filipino", "yue": "Cantonese", "cmn": "Mandarin",
+}
@@ -0,0 +1,98 @@
+package client
+
+import "testing"
+
+func TestParseLanguageTag(t *testing.T) {
+	tests := map[string]string{
+		"en-US":      "en-US",
+		"en_gb":      "en-GB",
+		"EN":         "en",
+		"zh-hant-tw": "zh-Hant-TW",
+		"es-419":     "es-419",
+		"fra":        "fr",
+		"ger-DE":     "de-DE",
+		"fil":        "fil",
+		"sr-Latn":    "sr-Latn",
+		"de-CH-1996": "de-CH-1996",
+	}
+	for input, want := range tests {
+		tag, err := ParseLanguageTag(input)
+		if err != nil || tag.String() != want {
+			t.Errorf("ParseLanguageTag(%q) = %q, %v, want %q", input, tag, err, want)
+		}
+	}
+
+	for _, input := range []string{"", "e", "English", "en-US!", "12", "en--", "en-toolongsubtag"} {
+		if tag, err := ParseLanguageTag(input); err == nil {
+			t.Errorf("ParseLanguageTag(%q) = %q, expected error", input, tag)
+		}
+	}
+}
+
+func TestLanguageMatch(t *testing.T) {
+	tests := []struct {
+		match    LanguageMatch
+		detected string
+		expected string
+		want     bool
+	}{
+		{MatchExact, "en-US", "en-US", true},
+		{MatchExact, "en-us", "en_US", true},
+		{MatchExact, "en", "en-US", false},
+		{MatchExact, "en-GB", "en-US", false},
+		{MatchPrefix, "en", "en-US", true},
+		{MatchPrefix, "en-GB", "en", true},
+		{MatchPrefix, "en-GB", "en-US", false},
+		{MatchPrefix, "zh-Hant-TW", "zh-Hant", true},
+		{MatchLanguage, "en-GB", "en-US", true},
+		{MatchLanguage, "fr-FR", "en-US", false},
+		{MatchLanguage, "unknown!", "en-US", false},
+	}
+	for _, tt := range tests {
+		if got := tt.match.Matches(tt.detected, tt.expected); got != tt.want {
+			t.Errorf("%s.Matches(%q, %q) = %v, want %v", tt.match, tt.detected, tt.expected, got, tt.want)
+		}
+	}
+
+	if match, err := ParseLanguageMatch(""); err != nil || match != MatchExact {
+		t.Errorf("ParseLanguageMatch(\"\") = %q, %v, want exact", match, err)
+	}
+	if _, err := ParseLanguageMatch("fuzzy"); err == nil {
+		t.Error("ParseLanguageMatch(\"fuzzy\"): expected error")
+	}
+}
+
+func TestLanguageName(t *testing.T) {
+	tests := map[string]string{
+		"en-US":   "English (US)",
+		"fr":      "French",
+		"pt-BR":   "Portuguese (BR)",
+		"zh-Hant": "Chinese (Hant)",
+		"xx-YY":   "xx-YY",
+		"bogus!":  "bogus!",
+	}
+	for tag, want := range tests {
+		if got := LanguageName(tag); got != want {
+			t.Errorf("LanguageName(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestLanguageFromFilename(t *testing.T) {
+	tests := map[string]string{
+		"episode1.fr.vtt":           "fr",
+		"/media/show/ep2.pt-BR.srt": "pt-BR",
+		"episode1.en.sdh.vtt":       "en",
+		"movie.2019.eng.srt":        "en",
+		"episode1.vtt":              "",
+		"episode1.final.vtt":        "",
+		"fr.vtt":                    "",
+		"episode1.xx.vtt":           "",
+	}
+	for path, want := range tests {
+		got, ok := LanguageFromFilename(path)
+		if got != want || ok != (want != "") {
+			t.Errorf("LanguageFromFilename(%q) = %q, %v, want %q", path, got, ok, want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the API while the circuit
+// breaker is open
+var ErrCircuitOpen = errors.New("language API circuit breaker is open")
+
+// CircuitBreaker stops calling a service that keeps failing. After
+// threshold consecutive failed calls it opens, and calls fail at once with
+// ErrCircuitOpen for the cooldown. Then a single call is let through: if it
+// succeeds the breaker closes, otherwise it stays open for another cooldown.
+//
+// One breaker is shared by every file of a batch, so a service that is down
+// costs a few timeouts rather than one per file. It is safe for concurrent
+// use.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int       // consecutive failed calls
+	openedAt time.Time // when the breaker last opened
+	probing  bool      // a call is testing the service after the cooldown
+}
+
+// NewCircuitBreaker returns a closed breaker that opens after threshold
+// consecutive failures. A threshold of 0 or less returns nil, which never
+// opens.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// Allow returns ErrCircuitOpen if a call must not be made now. Every call
+// allowed must be followed by Record.
+func (b *CircuitBreaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.probing || b.now().Sub(b.openedAt) < b.cooldown {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// Record reports the outcome of an allowed call. Errors that mean the
+// service could not answer, timeouts included, count as failures; any
+// answer, even a bad one, shows the service is up. A call its caller
+// cancelled is recorded with context.Canceled and counts as neither.
+func (b *CircuitBreaker) Record(err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	switch {
+	case errors.Is(err, context.Canceled):
+	case err != nil && unavailable(err):
+		b.failures++
+		if b.failures >= b.threshold {
+			b.openedAt = b.now()
+		}
+	default:
+		b.failures = 0
+	}
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+	down := errors.New("connection refused")
+
+	b.Record(down)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected the breaker to stay closed after one failure, got %v", err)
+	}
+	b.Record(down)
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected the breaker to open after two failures, got %v", err)
+	}
+
+	// After the cooldown a single call tests the service
+	now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected a probe after the cooldown, got %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected other calls to wait for the probe, got %v", err)
+	}
+	b.Record(&StatusError{StatusCode: 503})
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected a failed probe to reopen the breaker, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected a second probe, got %v", err)
+	}
+	// Any answer closes the breaker, even one the API rejects
+	b.Record(&StatusError{StatusCode: 400})
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected the breaker to close, got %v", err)
+	}
+	b.Record(nil)
+
+	// Cancelled calls are neither successes nor failures
+	b.Record(down)
+	b.Record(context.Canceled)
+	b.Record(down)
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected cancellation not to reset the failures, got %v", err)
+	}
+
+	var disabled *CircuitBreaker
+	disabled.Record(down)
+	if NewCircuitBreaker(0, time.Minute) != nil || disabled.Allow() != nil {
+		t.Error("Expected a nil breaker never to open")
+	}
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// LanguageDetection is a detector's verdict on a piece of text
+type LanguageDetection struct {
+	Language   string  // BCP 47 tag, e.g. "en-US"
+	Confidence float64 // 0 to 1, or 0 when the detector does not report one
+}
+
+// LanguageDetector identifies the language caption text is written in.
+// Implementations must be safe for concurrent use, as batch runs share one.
+type LanguageDetector interface {
+	DetectLanguage(text string) (LanguageDetection, error)
+}
+
+// ContextDetector is a LanguageDetector whose calls can be cancelled, such
+// as one that makes network requests
+type ContextDetector interface {
+	LanguageDetector
+	DetectLanguageContext(ctx context.Context, text string) (LanguageDetection, error)
+}
+
+// ValidateLanguageWith detects the language of captionText with detector and
+// checks it against expectedLang. Tags must match exactly, ignoring case and
+// separators; callers wanting a looser match recompute Valid with a
+// LanguageMatch.
+func ValidateLanguageWith(detector LanguageDetector, captionText string, expectedLang string) (LanguageValidationResult, error) {
+	return ValidateLanguageContext(context.Background(), detector, captionText, expectedLang)
+}
+
+// ValidateLanguageContext is ValidateLanguageWith for a call that ctx can
+// cancel. Detectors that are not ContextDetectors run to completion.
+func ValidateLanguageContext(ctx context.Context, detector LanguageDetector, captionText string, expectedLang string) (LanguageValidationResult, error) {
+	var detection LanguageDetection
+	var err error
+	if cd, ok := detector.(ContextDetector); ok {
+		detection, err = cd.DetectLanguageContext(ctx, captionText)
+	} else {
+		detection, err = detector.DetectLanguage(captionText)
+	}
+	if err != nil {
+		return LanguageValidationResult{}, err
+	}
+
+	return LanguageValidationResult{
+		Valid:        MatchExact.Matches(detection.Language, expectedLang),
+		Type:         "incorrect_language",
+		Language:     detection.Language,
+		ExpectedLang: expectedLang,
+		Confidence:   detection.Confidence,
+	}, nil
+}
+
+// HTTPDetector is the LanguageDetector backed by the language validation
+// API: it POSTs the text as text/plain and reads back {"lang": "..."}
+type HTTPDetector struct {
+	client *http.Client
+	url    string
+
+	// Retry repeats requests that fail with a network error or a busy
+	// status such as 503. NewHTTPDetector sets DefaultRetryPolicy.
+	Retry RetryPolicy
+	// Breaker, when set, stops calling the API once it keeps failing
+	Breaker *CircuitBreaker
+}
+
+// NewHTTPDetector returns a detector that calls the API at apiURL. A nil
+// client means one from NewHTTPClient.
+func NewHTTPDetector(client *http.Client, apiURL string) *HTTPDetector {
+	if client == nil {
+		client = NewHTTPClient()
+	}
+	return &HTTPDetector{client: client, url: apiURL, Retry: DefaultRetryPolicy}
+}
+
+// DetectLanguage implements LanguageDetector
+func (d *HTTPDetector) DetectLanguage(text string) (LanguageDetection, error) {
+	return d.DetectLanguageContext(context.Background(), text)
+}
+
+// DetectLanguageContext implements ContextDetector. Retries and the waits
+// between them stop when ctx is done.
+func (d *HTTPDetector) DetectLanguageContext(ctx context.Context, text string) (LanguageDetection, error) {
+	if err := d.Breaker.Allow(); err != nil {
+		return LanguageDetection{}, err
+	}
+
+	var detection LanguageDetection
+	err := d.Retry.do(ctx, func() error {
+		var err error
+		detection, err = d.request(ctx, text)
+		return err
+	})
+	if ctx.Err() != nil {
+		d.Breaker.Record(context.Canceled)
+	} else {
+		d.Breaker.Record(err)
+	}
+	return detection, err
+}
+
+// request makes a single call to the API
+func (d *HTTPDetector) request(ctx context.Context, text string) (LanguageDetection, error) {
+	// Create request with plaintext body
+	req, err := http.NewRequestWithContext(ctx, "POST", d.url, strings.NewReader(text))
+	if err != nil {
+		return LanguageDetection{}, &permanentError{fmt.Errorf("error creating request: %w", err)}
+	}
+	req.Header.Set("Content-Type", "text/plain")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return LanguageDetection{}, fmt.Errorf("error sending request to language API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return LanguageDetection{}, &StatusError{StatusCode: resp.StatusCode, Body: string(body), RetryAfter: retryAfter(resp)}
+	}
+
+	var langResp LanguageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&langResp); err != nil {
+		return LanguageDetection{}, &permanentError{fmt.Errorf("error parsing API response: %w", err)}
+	}
+
+	return LanguageDetection{Language: langResp.Lang}, nil
+}
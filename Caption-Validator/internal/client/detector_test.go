@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeDetector answers with a fixed language, or fails
+type fakeDetector struct {
+	language string
+	err      error
+}
+
+func (d fakeDetector) DetectLanguage(text string) (LanguageDetection, error) {
+	return LanguageDetection{Language: d.language}, d.err
+}
+
+func TestValidateLanguageWith(t *testing.T) {
+	result, err := ValidateLanguageWith(fakeDetector{language: "fr-FR"}, "Bonjour", "fr-FR")
+	if err != nil || !result.Valid || result.Language != "fr-FR" || result.ExpectedLang != "fr-FR" {
+		t.Errorf("ValidateLanguageWith() = %+v, %v", result, err)
+	}
+
+	result, err = ValidateLanguageWith(fakeDetector{language: "es-ES"}, "Hola", DefaultExpectedLanguage)
+	if err != nil || result.Valid || result.Type != "incorrect_language" {
+		t.Errorf("Expected an incorrect_language result, got %+v, %v", result, err)
+	}
+
+	failure := errors.New("detector down")
+	if _, err := ValidateLanguageWith(fakeDetector{err: failure}, "Hello", DefaultExpectedLanguage); !errors.Is(err, failure) {
+		t.Errorf("Expected the detector's error, got %v", err)
+	}
+}
+
+// quickRetry retries without making the tests wait
+var quickRetry = RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+func TestHTTPDetectorRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int // answered in turn, then 200
+		retry    RetryPolicy
+		wantErr  bool
+		calls    int32
+	}{
+		{"Recovers from 503s", []int{503, 503}, quickRetry, false, 3},
+		{"Gives up after the retries", []int{503, 502, 504}, quickRetry, true, 3},
+		{"Does not retry client errors", []int{400}, quickRetry, true, 1},
+		{"Does not retry server bugs", []int{500}, quickRetry, true, 1},
+		{"Retries rate limits", []int{429}, quickRetry, false, 2},
+		{"No retries", []int{503}, RetryPolicy{}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if n := int(calls.Add(1)); n <= len(tt.statuses) {
+					w.WriteHeader(tt.statuses[n-1])
+					return
+				}
+				w.Write([]byte(`{"lang": "en-US"}`))
+			}))
+			defer server.Close()
+
+			detector := NewHTTPDetector(server.Client(), server.URL)
+			detector.Retry = tt.retry
+			detection, err := detector.DetectLanguage("Hello")
+			if (err != nil) != tt.wantErr || calls.Load() != tt.calls {
+				t.Errorf("Got %+v, %v after %d calls, want error %v after %d", detection, err, calls.Load(), tt.wantErr, tt.calls)
+			}
+			var status *StatusError
+			if tt.wantErr && !errors.As(err, &status) {
+				t.Errorf("Expected a StatusError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, max := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		for i := 0; i < 20; i++ {
+			if d := policy.delay(attempt, nil); d < max/2 || d > max {
+				t.Errorf("delay(%d) = %v, want between %v and %v", attempt, d, max/2, max)
+			}
+		}
+	}
+
+	// The service may ask for a longer wait, within MaxDelay
+	if d := policy.delay(0, &StatusError{StatusCode: 503, RetryAfter: 700 * time.Millisecond}); d != 700*time.Millisecond {
+		t.Errorf("Expected the Retry-After delay, got %v", d)
+	}
+	if d := policy.delay(0, &StatusError{StatusCode: 503, RetryAfter: time.Minute}); d != time.Second {
+		t.Errorf("Expected Retry-After to be capped, got %v", d)
+	}
+}
+
+func TestHTTPDetectorCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	detector := NewHTTPDetector(server.Client(), server.URL)
+	detector.Retry = RetryPolicy{MaxRetries: 5, BaseDelay: time.Minute, MaxDelay: time.Minute}
+	detector.Breaker = NewCircuitBreaker(1, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	_, err := ValidateLanguageContext(ctx, detector, "Hello", "en-US")
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(started) > 5*time.Second {
+		t.Errorf("Expected the wait to end with the context, got %v after %v", err, time.Since(started))
+	}
+	// Giving up is not held against the service
+	if err := detector.Breaker.Allow(); err != nil {
+		t.Errorf("Expected the breaker to stay closed, got %v", err)
+	}
+}
@@ -1,14 +1,15 @@
 package client
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"time"
 )
 
+// DefaultExpectedLanguage is the language captions are expected to be in unless configured otherwise
+const DefaultExpectedLanguage = "en-US"
+
 // LanguageResponse represents the response from language validation API
 type LanguageResponse struct {
 	Lang string `json:"lang"`
@@ -20,6 +21,12 @@ type LanguageValidationResult struct {
 	Type         string
 	Language     string
 	ExpectedLang string
+	Confidence   float64 // the detector's confidence, 0 when it reports none
+}
+
+// Recommendation tells the user which language the captions should be in
+func (lvr LanguageValidationResult) Recommendation() string {
+	return fmt.Sprintf("Caption text should be in %s language", LanguageName(lvr.ExpectedLang))
 }
 
 // JSON returns the JSON representation of the validation result
@@ -28,7 +35,7 @@ func (lvr LanguageValidationResult) JSON() string {
 		"type":            "incorrect_language",
 		"detected":        lvr.Language,
 		"expected":        lvr.ExpectedLang,
-		"recommendation":  "Caption text should be in English (US) language",
+		"recommendation":  lvr.Recommendation(),
 	}
 	
 	jsonBytes, err := json.Marshal(result)
@@ -39,49 +46,28 @@ func (lvr LanguageValidationResult) JSON() string {
 	return string(jsonBytes)
 }
 
+// DefaultTimeout bounds each request to the language validation API
+const DefaultTimeout = 10 * time.Second
+
+// NewHTTPClient returns an HTTP client configured for the language validation API.
+// Batch runs share a single client so connections are reused across files.
+func NewHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: DefaultTimeout,
+	}
+}
+
+// defaultClient is shared by the calls to ValidateLanguage, so they reuse
+// connections rather than opening new ones
+var defaultClient = NewHTTPClient()
+
 // ValidateLanguage sends caption text to the language validation API
 func ValidateLanguage(apiURL string, captionText string) (LanguageValidationResult, error) {
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-	
-	// Create request with plaintext body
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBufferString(captionText))
-	if err != nil {
-		return LanguageValidationResult{}, fmt.Errorf("error creating request: %w", err)
-	}
-	
-	// Set content type to plain text
-	req.Header.Set("Content-Type", "text/plain")
-	
-	// Send request
-	resp, err := client.Do(req)
-	if err != nil {
-		return LanguageValidationResult{}, fmt.Errorf("error sending request to language API: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	// Check response status
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return LanguageValidationResult{}, fmt.Errorf("API returned non-200 status: %d, body: %s", resp.StatusCode, body)
-	}
-	
-	// Parse response
-	var langResp LanguageResponse
-	if err := json.NewDecoder(resp.Body).Decode(&langResp); err != nil {
-		return LanguageValidationResult{}, fmt.Errorf("error parsing API response: %w", err)
-	}
-	
-	// Validate language is en-US
-	const expectedLang = "en-US"
-	isValid := langResp.Lang == expectedLang
-	
-	return LanguageValidationResult{
-		Valid:        isValid,
-		Type:         "incorrect_language",
-		Language:     langResp.Lang,
-		ExpectedLang: expectedLang,
-	}, nil
+	return ValidateLanguageWithClient(defaultClient, apiURL, captionText, DefaultExpectedLanguage)
+}
+
+// ValidateLanguageWithClient sends caption text to the language validation API using the given
+// client and checks the detected language against expectedLang
+func ValidateLanguageWithClient(client *http.Client, apiURL string, captionText string, expectedLang string) (LanguageValidationResult, error) {
+	return ValidateLanguageWith(NewHTTPDetector(client, apiURL), captionText, expectedLang)
 }
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides how often and how patiently a failed API request is
+// repeated. Only failures that may go away are retried: network errors and
+// the statuses in retryableStatuses.
+type RetryPolicy struct {
+	MaxRetries int           // further attempts after the first; 0 never retries
+	BaseDelay  time.Duration // delay before the first retry, doubled for each next one
+	MaxDelay   time.Duration // upper bound of any delay, including a Retry-After
+}
+
+// DefaultRetryPolicy retries twice, after about half a second and a second
+var DefaultRetryPolicy = RetryPolicy{MaxRetries: 2, BaseDelay: 500 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+// retryableStatuses are the statuses of a busy or restarting service
+var retryableStatuses = map[int]bool{
+	http.StatusRequestTimeout:     true,
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// StatusError is returned when the API answers with a status other than 200
+type StatusError struct {
+	StatusCode int
+	Body       string
+	// RetryAfter is the delay the service asked for, 0 if it did not
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API returned non-200 status: %d, body: %s", e.StatusCode, e.Body)
+}
+
+// retryAfter reads a Retry-After header given in seconds
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// unavailable reports whether err means the service could not answer, as
+// opposed to answering badly. Such errors, timeouts included, are retried
+// and count against the circuit breaker.
+func unavailable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var status *StatusError
+	if errors.As(err, &status) {
+		return retryableStatuses[status.StatusCode]
+	}
+	var permanent *permanentError
+	return !errors.As(err, &permanent)
+}
+
+// permanentError wraps a failure that trying again cannot fix, such as a
+// malformed URL or a response that cannot be read
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// delay returns how long to wait before retry number attempt (from 0): the
+// base delay doubled per attempt, capped at MaxDelay, with jitter so that
+// the workers of a batch do not retry in step. A longer Retry-After from the
+// service wins, up to MaxDelay.
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	d := p.BaseDelay << attempt
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d > 0 {
+		d = d/2 + rand.N(d/2+1)
+	}
+
+	var status *StatusError
+	if errors.As(err, &status) && status.RetryAfter > d {
+		d = status.RetryAfter
+		if p.MaxDelay > 0 && d > p.MaxDelay {
+			d = p.MaxDelay
+		}
+	}
+	return d
+}
+
+// do calls attempt until it succeeds, fails for good or the retries run
+// out, waiting between attempts as the policy says
+func (p RetryPolicy) do(ctx context.Context, attempt func() error) error {
+	for i := 0; ; i++ {
+		err := attempt()
+		if err == nil || i >= p.MaxRetries || !unavailable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(p.delay(i, err))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (after %d attempts, last error: %v)", ctx.Err(), i+1, err)
+		case <-timer.C:
+		}
+	}
+}
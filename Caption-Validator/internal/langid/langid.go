@@ -0,0 +1,329 @@
+// Package langid identifies the language of caption text offline, so the
+// language rule also works where the language validation API cannot be
+// reached. Languages with a script of their own (Greek, Thai, Korean, ...)
+// are recognised by their script. Languages that share the Latin, Cyrillic
+// or Arabic script are told apart by comparing the character trigrams of
+// the text with profiles built from the embedded corpus.
+package langid
+
+import (
+	"embed"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"caption-validator/internal/client"
+)
+
+// corpus holds one text per language, named after its BCP 47 tag. Every
+// text is the same passage of everyday dialogue, so the profiles differ by
+// language rather than by topic. The languages that are hardest to tell
+// apart, Danish, Norwegian and Swedish, and Indonesian and Malay, have a
+// second shared passage, since one holds too few of the words that differ.
+//
+//go:embed corpus/*.txt
+var corpus embed.FS
+
+// Undetermined is the tag reported for text without any letters, or too
+// few to compare with the profiles
+const Undetermined = "und"
+
+// minLetters is the least text, in letters, compared with the profiles.
+// Shorter cues such as "Yes." match a random language about as well as
+// their own.
+const minLetters = 8
+
+// temperature turns the differences between the profiles' log likelihoods
+// of a text into confidences. The differences grow with the length of the
+// text, so a few words rarely give a confident answer while a few sentences
+// do; a higher value makes the detector less certain.
+const temperature = 5
+
+// script is a writing system. Scripts written in one language identify it
+// outright; for the others the language is decided by the profiles.
+type script struct {
+	name     string
+	table    *unicode.RangeTable
+	language string
+}
+
+var scripts = []script{
+	{"Latin", unicode.Latin, ""},
+	{"Cyrillic", unicode.Cyrillic, ""},
+	{"Arabic", unicode.Arabic, ""},
+	{"Greek", unicode.Greek, "el"},
+	{"Hebrew", unicode.Hebrew, "he"},
+	{"Devanagari", unicode.Devanagari, "hi"},
+	{"Bengali", unicode.Bengali, "bn"},
+	{"Gurmukhi", unicode.Gurmukhi, "pa"},
+	{"Gujarati", unicode.Gujarati, "gu"},
+	{"Tamil", unicode.Tamil, "ta"},
+	{"Telugu", unicode.Telugu, "te"},
+	{"Kannada", unicode.Kannada, "kn"},
+	{"Malayalam", unicode.Malayalam, "ml"},
+	{"Sinhala", unicode.Sinhala, "si"},
+	{"Thai", unicode.Thai, "th"},
+	{"Lao", unicode.Lao, "lo"},
+	{"Khmer", unicode.Khmer, "km"},
+	{"Myanmar", unicode.Myanmar, "my"},
+	{"Georgian", unicode.Georgian, "ka"},
+	{"Armenian", unicode.Armenian, "hy"},
+	{"Ethiopic", unicode.Ethiopic, "am"},
+	{"Hangul", unicode.Hangul, "ko"},
+	{"Kana", kana, "ja"},
+	{"Han", unicode.Han, "zh"},
+}
+
+// kana covers the Japanese syllabaries. Text with enough kana is Japanese
+// even though most of its characters may be Han.
+var kana = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x3040, Hi: 0x309f, Stride: 1}, // Hiragana
+		{Lo: 0x30a0, Hi: 0x30ff, Stride: 1}, // Katakana
+		{Lo: 0x31f0, Hi: 0x31ff, Stride: 1}, // Katakana phonetic extensions
+		{Lo: 0xff66, Hi: 0xff9d, Stride: 1}, // Halfwidth katakana
+	},
+}
+
+// minKanaShare is the share of kana among Han and kana characters above
+// which text is taken to be Japanese rather than Chinese
+const minKanaShare = 0.1
+
+// model holds the log probabilities of the trigrams or words of one
+// language. Those the corpus does not have get the smoothed probability
+// unseen.
+type model struct {
+	logProbs map[string]float64
+	unseen   float64
+}
+
+// profile is what the corpus says about one language
+type profile struct {
+	language string
+	script   string
+	trigrams model
+	words    model
+}
+
+// smoothing is added to every count, and vocabulary is the assumed number of
+// distinct trigrams or words per language, so that those missing from a
+// corpus count against a language without ruling it out
+const (
+	smoothing  = 0.1
+	vocabulary = 10000
+)
+
+// newModel estimates probabilities from corpus counts
+func newModel(counts map[string]float64) model {
+	var total float64
+	for _, count := range counts {
+		total += count
+	}
+	denominator := math.Log(total + smoothing*vocabulary)
+	m := model{
+		logProbs: make(map[string]float64, len(counts)),
+		unseen:   math.Log(smoothing) - denominator,
+	}
+	for key, count := range counts {
+		m.logProbs[key] = math.Log(count+smoothing) - denominator
+	}
+	return m
+}
+
+// logLikelihood is the summed log probability of counts under the model
+func (m model) logLikelihood(counts map[string]float64) float64 {
+	var sum float64
+	for key, count := range counts {
+		logProb, ok := m.logProbs[key]
+		if !ok {
+			logProb = m.unseen
+		}
+		sum += count * logProb
+	}
+	return sum
+}
+
+// score is the log likelihood of the text under the profile: that of its
+// trigrams, plus that of its words scaled to count as much. Trigrams cope
+// with words the corpus lacks; whole words tell apart languages as close as
+// Danish and Norwegian, which share most trigrams.
+func (p profile) score(s sample) float64 {
+	return p.trigrams.logLikelihood(s.trigrams) + p.words.logLikelihood(s.words)*s.trigramCount/s.wordCount
+}
+
+// loadProfiles builds the profiles from the corpus once
+var loadProfiles = sync.OnceValue(func() []profile {
+	entries, err := corpus.ReadDir("corpus")
+	if err != nil {
+		panic("langid: missing corpus: " + err.Error())
+	}
+	profiles := make([]profile, 0, len(entries))
+	for _, entry := range entries {
+		data, err := corpus.ReadFile(path.Join("corpus", entry.Name()))
+		if err != nil {
+			panic("langid: unreadable corpus: " + err.Error())
+		}
+		sample := analyse(string(data))
+		profiles = append(profiles, profile{
+			language: strings.TrimSuffix(entry.Name(), ".txt"),
+			script:   sample.script.name,
+			trigrams: newModel(sample.trigrams),
+			words:    newModel(sample.words),
+		})
+	}
+	return profiles
+})
+
+// Languages lists the tags the detector can report, other than Undetermined
+func Languages() []string {
+	seen := make(map[string]bool)
+	for _, s := range scripts {
+		if s.language != "" {
+			seen[s.language] = true
+		}
+	}
+	for _, p := range loadProfiles() {
+		seen[p.language] = true
+	}
+	languages := make([]string, 0, len(seen))
+	for language := range seen {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Detector is the built-in client.LanguageDetector. It reports bare
+// language tags such as "fr", without a region, and never fails.
+type Detector struct{}
+
+// New returns the built-in detector
+func New() *Detector {
+	return &Detector{}
+}
+
+// DetectLanguage implements client.LanguageDetector. The confidence is the
+// share of the text's letters written in the detected language's script,
+// reduced when other languages of that script match the text almost as well,
+// which short text always does. Text in a shared script with fewer than
+// minLetters letters is Undetermined.
+func (d *Detector) DetectLanguage(text string) (client.LanguageDetection, error) {
+	sample := analyse(text)
+	if sample.scriptLetters == 0 {
+		return client.LanguageDetection{Language: Undetermined}, nil
+	}
+	share := float64(sample.scriptLetters) / float64(sample.letters)
+	if sample.script.language != "" {
+		return client.LanguageDetection{Language: sample.script.language, Confidence: share}, nil
+	}
+
+	if sample.scriptLetters < minLetters {
+		return client.LanguageDetection{Language: Undetermined}, nil
+	}
+
+	var candidates []profile
+	for _, p := range loadProfiles() {
+		if p.script == sample.script.name {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		return client.LanguageDetection{Language: Undetermined}, nil
+	}
+
+	scores := make([]float64, len(candidates))
+	best := 0
+	for i, p := range candidates {
+		scores[i] = p.score(sample)
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+
+	// Softmax over the scores, relative to the best for stability
+	var total float64
+	for _, score := range scores {
+		total += math.Exp((score - scores[best]) / temperature)
+	}
+	return client.LanguageDetection{
+		Language:   candidates[best].language,
+		Confidence: share / total,
+	}, nil
+}
+
+// sample is what the detector needs to know about a text
+type sample struct {
+	letters       int    // letters in any script
+	script        script // the script most letters are written in
+	scriptLetters int    // letters in that script
+	trigrams      map[string]float64
+	words         map[string]float64
+	trigramCount  float64
+	wordCount     float64
+}
+
+// analyse finds the dominant script of text and counts the character
+// trigrams of its words in that script. Words are lower-cased and padded
+// with spaces, so trigrams also capture how words start and end.
+func analyse(text string) sample {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsMark(r)
+	})
+
+	counts := make([]int, len(scripts))
+	wordScripts := make([]int, len(words))
+	s := sample{trigrams: make(map[string]float64), words: make(map[string]float64)}
+	for i, word := range words {
+		wordScripts[i] = -1
+		for _, r := range word {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			s.letters++
+			for j, sc := range scripts {
+				if unicode.Is(sc.table, r) {
+					counts[j]++
+					if wordScripts[i] < 0 {
+						wordScripts[i] = j
+					}
+					break
+				}
+			}
+		}
+	}
+
+	// Han characters count as Japanese when there is enough kana around them
+	kanaIndex, hanIndex := len(scripts)-2, len(scripts)-1
+	if cjk := counts[kanaIndex] + counts[hanIndex]; cjk > 0 && float64(counts[kanaIndex]) >= minKanaShare*float64(cjk) {
+		counts[kanaIndex], counts[hanIndex] = cjk, 0
+	}
+
+	dominant := 0
+	for i, count := range counts {
+		if count > counts[dominant] {
+			dominant = i
+		}
+	}
+	s.script, s.scriptLetters = scripts[dominant], counts[dominant]
+	if s.script.language != "" {
+		return s
+	}
+
+	for i, word := range words {
+		if wordScripts[i] != dominant {
+			continue
+		}
+		word = strings.ToLower(word)
+		s.words[word]++
+		s.wordCount++
+		runes := []rune(" " + word + " ")
+		for j := 0; j+3 <= len(runes); j++ {
+			s.trigrams[string(runes[j:j+3])]++
+			s.trigramCount++
+		}
+	}
+	return s
+}
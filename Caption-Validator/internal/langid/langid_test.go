@@ -0,0 +1,150 @@
+package langid
+
+import (
+	"testing"
+
+	"caption-validator/internal/client"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	// None of these sentences are in the corpus
+	samples := map[string]string{
+		"en": "The weather was terrible yesterday, so we stayed at home and watched old movies all afternoon.",
+		"fr": "Il faisait un temps horrible hier, alors nous sommes restés à la maison pour regarder de vieux films tout l'après-midi.",
+		"es": "Ayer hizo un tiempo horrible, así que nos quedamos en casa viendo películas antiguas toda la tarde.",
+		"pt": "Ontem o tempo estava horrível, então ficamos em casa assistindo filmes antigos a tarde toda.",
+		"it": "Ieri il tempo era orribile, quindi siamo rimasti a casa a guardare vecchi film tutto il pomeriggio.",
+		"de": "Gestern war das Wetter schrecklich, also sind wir zu Hause geblieben und haben den ganzen Nachmittag alte Filme geschaut.",
+		"nl": "Gisteren was het weer verschrikkelijk, dus zijn we thuisgebleven en hebben we de hele middag oude films gekeken.",
+		"sv": "Vädret var hemskt i går, så vi stannade hemma och tittade på gamla filmer hela eftermiddagen.",
+		"da": "Vejret var forfærdeligt i går, så vi blev hjemme og så gamle film hele eftermiddagen.",
+		"nb": "Været var forferdelig i går, så vi ble hjemme og så på gamle filmer hele ettermiddagen.",
+		"pl": "Wczoraj pogoda była okropna, więc zostaliśmy w domu i przez całe popołudnie oglądaliśmy stare filmy.",
+		"cs": "Včera bylo hrozné počasí, takže jsme zůstali doma a celé odpoledne jsme se dívali na staré filmy.",
+		"sk": "Včera bolo hrozné počasie, takže sme zostali doma a celé popoludnie sme pozerali staré filmy.",
+		"hr": "Jučer je vrijeme bilo užasno, pa smo ostali kod kuće i cijelo poslijepodne gledali stare filmove.",
+		"hu": "Tegnap borzalmas volt az idő, ezért otthon maradtunk, és egész délután régi filmeket néztünk.",
+		"ro": "Ieri vremea a fost groaznică, așa că am stat acasă și ne-am uitat la filme vechi toată după-amiaza.",
+		"fi": "Eilen sää oli kamala, joten jäimme kotiin ja katsoimme vanhoja elokuvia koko iltapäivän.",
+		"tr": "Dün hava berbattı, bu yüzden evde kaldık ve bütün öğleden sonra eski filmler izledik.",
+		"id": "Kemarin cuacanya buruk sekali, jadi kami tinggal di rumah dan menonton film lama sepanjang sore.",
+		"ms": "Semalam cuaca sangat teruk, jadi kami duduk di rumah dan menonton filem lama sepanjang petang.",
+		"vi": "Hôm qua thời tiết rất tệ nên chúng tôi ở nhà và xem phim cũ suốt cả buổi chiều.",
+		"ru": "Вчера была ужасная погода, поэтому мы остались дома и весь день смотрели старые фильмы.",
+		"uk": "Вчора була жахлива погода, тому ми залишилися вдома і весь день дивилися старі фільми.",
+		"bg": "Вчера времето беше ужасно, затова си останахме вкъщи и цял следобед гледахме стари филми.",
+		"sr": "Јуче је време било ужасно, па смо остали код куће и цело поподне гледали старе филмове.",
+		"ar": "كان الطقس سيئا جدا أمس، لذلك بقينا في البيت وشاهدنا أفلاما قديمة طوال فترة بعد الظهر.",
+		"fa": "دیروز هوا خیلی بد بود، برای همین در خانه ماندیم و تمام بعدازظهر فیلم‌های قدیمی دیدیم.",
+		"el": "Χθες ο καιρός ήταν απαίσιος, οπότε μείναμε σπίτι και βλέπαμε παλιές ταινίες όλο το απόγευμα.",
+		"he": "אתמול מזג האוויר היה נורא, אז נשארנו בבית וראינו סרטים ישנים כל אחר הצהריים.",
+		"hi": "कल मौसम बहुत खराब था, इसलिए हम घर पर रहे और पूरी दोपहर पुरानी फिल्में देखीं।",
+		"th": "เมื่อวานอากาศแย่มาก เราเลยอยู่บ้านดูหนังเก่าทั้งบ่าย",
+		"ko": "어제는 날씨가 너무 안 좋아서 우리는 집에서 오후 내내 옛날 영화를 봤어요.",
+		"ja": "昨日は天気がひどかったので、家で一日中古い映画を見ていました。",
+		"zh": "昨天天气很糟糕，所以我们整个下午都待在家里看老电影。",
+	}
+
+	detector := New()
+	for want, text := range samples {
+		t.Run(want, func(t *testing.T) {
+			detection, err := detector.DetectLanguage(text)
+			if err != nil || detection.Language != want {
+				t.Errorf("DetectLanguage() = %+v, %v, want %s", detection, err, want)
+			}
+			if detection.Confidence < 0.5 || detection.Confidence > 1 {
+				t.Errorf("Confidence %v out of range", detection.Confidence)
+			}
+		})
+	}
+}
+
+func TestDetectLanguageConfidence(t *testing.T) {
+	detector := New()
+
+	for _, text := range []string{"", "12:30 -- !!", "♪ ♪"} {
+		if detection, _ := detector.DetectLanguage(text); detection.Language != Undetermined || detection.Confidence != 0 {
+			t.Errorf("DetectLanguage(%q) = %+v, want %s", text, detection, Undetermined)
+		}
+	}
+
+	english := "I told you to wait for me here, but you never listen to anything I say."
+	pure, _ := detector.DetectLanguage(english)
+	mixed, _ := detector.DetectLanguage(english + " Вчера была ужасная погода.")
+	if mixed.Language != "en" || mixed.Confidence >= pure.Confidence {
+		t.Errorf("Text in two scripts should be less certain: %+v vs %+v", mixed, pure)
+	}
+
+	short, _ := detector.DetectLanguage("Hola")
+	if short.Confidence >= pure.Confidence {
+		t.Errorf("A single word should be less certain: %+v vs %+v", short, pure)
+	}
+
+	// Norwegian and Danish differ in a few words
+	for text, want := range map[string]string{
+		"Hvor skal du hen? Jeg sa at du skulle vente på meg her.":          "nb",
+		"Hvor skal du hen? Jeg sagde, at du skulle vente på mig her.":      "da",
+		"Jeg vet ikke hva vi skal gjøre nå, men vi må finne noe snart.":    "nb",
+		"Jeg ved ikke, hvad vi skal gøre nu, men vi må finde noget snart.": "da",
+	} {
+		if detection, _ := detector.DetectLanguage(text); detection.Language != want {
+			t.Errorf("DetectLanguage(%q) = %+v, want %s", text, detection, want)
+		}
+	}
+}
+
+func TestDetectLanguageShortText(t *testing.T) {
+	detector := New()
+
+	// Too short to compare with the profiles
+	for _, text := range []string{"Yes.", "Okay.", "No way!", "What?", "Hola"} {
+		if detection, _ := detector.DetectLanguage(text); detection.Language != Undetermined || detection.Confidence != 0 {
+			t.Errorf("DetectLanguage(%q) = %+v, want %s", text, detection, Undetermined)
+		}
+	}
+
+	// A few words may be detected wrongly, but not with the confidence the
+	// language rule acts on by default
+	short := map[string]string{
+		"Thank you.":         "en",
+		"I know, I know.":    "en",
+		"Bonjour, monsieur.": "fr",
+		"Danke schön.":       "de",
+		"Hva sa du nå?":      "nb",
+		"Muchas gracias.":    "es",
+		"Va bene, grazie.":   "it",
+		"Dziękuję bardzo.":   "pl",
+	}
+	for text, want := range short {
+		detection, _ := detector.DetectLanguage(text)
+		if detection.Language != want && detection.Confidence >= 0.5 {
+			t.Errorf("DetectLanguage(%q) = %+v, a confident wrong answer (want %s)", text, detection, want)
+		}
+	}
+
+	// Sentences are detected with confidence, and more surely than words
+	words, _ := detector.DetectLanguage("I know, I know.")
+	sentence, _ := detector.DetectLanguage("I know, I know. Just give me a second to find my keys before we go.")
+	if sentence.Language != "en" || sentence.Confidence < 0.9 || words.Confidence >= sentence.Confidence {
+		t.Errorf("Expected a sentence to be surer than a few words: %+v vs %+v", sentence, words)
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	languages := Languages()
+	if len(languages) < 50 {
+		t.Errorf("Expected at least 50 languages, got %d", len(languages))
+	}
+	for _, language := range languages {
+		if _, err := client.ParseLanguageTag(language); err != nil || client.LanguageName(language) == language {
+			t.Errorf("Language %q is not a known BCP 47 tag", language)
+		}
+	}
+
+	// Every corpus must be written in a script the detector shares between languages
+	for _, p := range loadProfiles() {
+		if p.script != "Latin" && p.script != "Cyrillic" && p.script != "Arabic" {
+			t.Errorf("Corpus %s is written in %s", p.language, p.script)
+		}
+	}
+}
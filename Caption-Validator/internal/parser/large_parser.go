@@ -29,6 +29,11 @@ func ParseLargeCaptionsFile(filePath string) ([]Caption, string, error) {
 		captions, err = parseChunkedWebVTT(file)
 	case FormatSRT:
 		captions, err = parseChunkedSRT(file)
+	case FormatTTML:
+		// The TTML parser already streams tokens, so it is memory-efficient as is
+		captions, err = parseTTML(file)
+	case FormatSCC:
+		captions, err = parseSCC(file)
 	default:
 		return nil, "", ErrUnsupportedFormat
 	}
@@ -36,6 +41,9 @@ func ParseLargeCaptionsFile(filePath string) ([]Caption, string, error) {
 	if err != nil {
 		return nil, "", err
 	}
+	if err := checkTimes(captions); err != nil {
+		return nil, "", err
+	}
 
 	return captions, format, nil
 }
@@ -56,8 +64,11 @@ func parseChunkedWebVTT(r io.Reader) ([]Caption, error) {
 		return nil, errors.New("missing WEBVTT header")
 	}
 
+	lineNumber := 1
+
 	// Skip header section until we find an empty line
 	for scanner.Scan() {
+		lineNumber++
 		if scanner.Text() == "" {
 			break
 		}
@@ -71,6 +82,7 @@ func parseChunkedWebVTT(r io.Reader) ([]Caption, error) {
 	// Process in chunks of 100 captions to avoid excessive memory usage
 	for scanner.Scan() {
 		line := scanner.Text()
+		lineNumber++
 
 		// Empty line indicates the end of a caption
 		if line == "" {
@@ -104,6 +116,7 @@ func parseChunkedWebVTT(r io.Reader) ([]Caption, error) {
 			currentCaption = Caption{
 				StartTime: startTime,
 				EndTime:   endTime,
+				Line:      lineNumber,
 			}
 			inCaption = true
 		} else if inCaption {
@@ -136,9 +149,11 @@ func parseChunkedSRT(r io.Reader) ([]Caption, error) {
 	var currentCaption Caption
 	var textLines []string
 	parseState := 0 // 0=index, 1=timestamp, 2=text
+	lineNumber := 0
 
 	for scanner.Scan() {
 		line := scanner.Text()
+		lineNumber++
 		trimmedLine := strings.TrimSpace(line)
 		
 		// Empty line means end of a caption block (unless we're at the beginning)
@@ -163,7 +178,7 @@ func parseChunkedSRT(r io.Reader) ([]Caption, error) {
 				if strings.Contains(trimmedLine, "-->") {
 					startTime, endTime, timeErr := parseSRTTimeline(trimmedLine)
 					if timeErr == nil {
-						currentCaption = Caption{Index: len(captions) + 1, StartTime: startTime, EndTime: endTime}
+						currentCaption = Caption{Index: len(captions) + 1, StartTime: startTime, EndTime: endTime, Line: lineNumber}
 						parseState = 2 // Skip to text parsing
 						continue
 					}
@@ -187,6 +202,7 @@ func parseChunkedSRT(r io.Reader) ([]Caption, error) {
 				}
 				currentCaption.StartTime = startTime
 				currentCaption.EndTime = endTime
+				currentCaption.Line = lineNumber
 				parseState = 2
 				textLines = nil
 			} else {
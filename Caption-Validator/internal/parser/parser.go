@@ -1,12 +1,12 @@
 package parser
 
 import (
-	"bytes"
+	"bufio"
 	"errors"
+	"fmt"
 	"io"
+	"math"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -15,6 +15,8 @@ import (
 const (
 	FormatWebVTT = "WebVTT"
 	FormatSRT    = "SRT"
+	FormatTTML   = "TTML"
+	FormatSCC    = "SCC"
 )
 
 // Errors
@@ -22,55 +24,59 @@ var (
 	ErrUnsupportedFormat = errors.New("unsupported caption format")
 )
 
+// detectHeaderSize is how much of a file format detection looks at; XML
+// prologs can push the TTML root well past the start
+const detectHeaderSize = 1024
+
+// ttmlRootPattern matches the opening <tt> element of a TTML/DFXP document,
+// with or without a namespace prefix
+var ttmlRootPattern = regexp.MustCompile(`<(?:[A-Za-z_][\w.-]*:)?tt[\s>]`)
+
+// tagPattern matches inline markup tags in caption text
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
 // Caption represents a single caption entry
 type Caption struct {
 	Index     int
 	StartTime float64 // in seconds
 	EndTime   float64 // in seconds
 	Text      string
+	// Line is the 1-based line of the cue in the source file: the timing line
+	// for WebVTT and SRT, the <p> element for TTML and the line whose data put
+	// the caption on screen for SCC. It is 0 for captions not read from a file.
+	Line int
 }
 
-// DetectCaptionFormat determines the format of a captions file
+// DetectCaptionFormat determines the format of a captions file from its
+// content, using its extension as a hint
 func DetectCaptionFormat(filePath string) (string, error) {
-	// Read the first few bytes to check magic bytes or signature
-	file, err := os.Open(filePath)
+	detection, err := SniffFile(filePath)
 	if err != nil {
 		return "", err
 	}
-	defer file.Close()
+	return detection.Result()
+}
 
-	// Check file extension as a hint
-	ext := strings.ToLower(filepath.Ext(filePath))
-	
-	// Read first 100 bytes for format detection
-	header := make([]byte, 100)
-	n, err := file.Read(header)
-	if err != nil && err != io.EOF {
+// DetectFormat determines the format of captions from their content alone,
+// reading at most the first 1KB of r
+func DetectFormat(r io.Reader) (string, error) {
+	header := make([]byte, detectHeaderSize)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 		return "", err
 	}
-	header = header[:n]
-
-	// Try using the 'file' command as a backup
-	cmd := exec.Command("file", filePath)
-	output, cmdErr := cmd.Output()
-	fileType := ""
-	if cmdErr == nil {
-		fileType = strings.ToLower(string(output))
-	}
+	return Sniff(header[:n], "").Result()
+}
 
-	// Check for WebVTT signature
-	if bytes.HasPrefix(header, []byte("WEBVTT")) || strings.Contains(string(header), "WEBVTT") {
-		return FormatWebVTT, nil
-	}
-	
-	// Check for SRT format
-	// SRT files typically start with a number (index), followed by time codes with arrow
-	if ext == ".srt" || strings.Contains(fileType, "subrip") || 
-		regexp.MustCompile(`^\d+\s*\r?\n\d{2}:\d{2}:\d{2},\d{3}\s*-->`).Match(header) {
-		return FormatSRT, nil
+// PeekFormat determines the format of captions from the start of a buffered
+// reader without consuming it, so the same reader can then be parsed. It
+// looks at up to 1KB, or the reader's buffer size if that is smaller.
+func PeekFormat(br *bufio.Reader) (string, error) {
+	detection, err := SniffReader(br, "")
+	if err != nil {
+		return "", err
 	}
-
-	return "", ErrUnsupportedFormat
+	return detection.Result()
 }
 
 // ParseCaptionsFile detects and parses a captions file
@@ -86,21 +92,66 @@ func ParseCaptionsFile(filePath string) ([]Caption, string, error) {
 	}
 	defer file.Close()
 
-	var captions []Caption
+	captions, err := ParseCaptions(file, format)
+	if err != nil {
+		return nil, "", err
+	}
 
+	return captions, format, nil
+}
+
+// ParseCaptions parses captions in a known format from r
+func ParseCaptions(r io.Reader, format string) ([]Caption, error) {
+	var captions []Caption
+	var err error
 	switch format {
 	case FormatWebVTT:
-		captions, err = parseWebVTT(file)
+		captions, err = parseWebVTT(r)
 	case FormatSRT:
-		captions, err = parseSRT(file)
+		captions, err = parseSRT(r)
+	case FormatTTML:
+		captions, err = parseTTML(r)
+	case FormatSCC:
+		captions, err = parseSCC(r)
 	default:
-		return nil, "", ErrUnsupportedFormat
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTimes(captions); err != nil {
+		return nil, err
+	}
+	return captions, nil
+}
+
+// checkTimes rejects captions whose times are not finite numbers, such as the
+// "NaN" and "Inf" that strconv.ParseFloat reads in a timestamp, which no
+// caption can be validated or ordered by
+func checkTimes(captions []Caption) error {
+	for _, caption := range captions {
+		for _, t := range []float64{caption.StartTime, caption.EndTime} {
+			if math.IsNaN(t) || math.IsInf(t, 0) {
+				return fmt.Errorf("caption %d has an invalid time", caption.Index)
+			}
+		}
 	}
+	return nil
+}
 
+// ParseCaptionsReader detects the format of captions from their content and
+// parses them, for input such as stdin that has no file name to go by
+func ParseCaptionsReader(r io.Reader) ([]Caption, string, error) {
+	br := bufio.NewReaderSize(r, detectHeaderSize)
+	format, err := PeekFormat(br)
 	if err != nil {
 		return nil, "", err
 	}
 
+	captions, err := ParseCaptions(br, format)
+	if err != nil {
+		return nil, "", err
+	}
 	return captions, format, nil
 }
 
@@ -110,10 +161,20 @@ func ExtractPlainText(captions []Caption) string {
 	
 	for _, caption := range captions {
 		// Remove HTML tags if present
-		text := regexp.MustCompile(`<[^>]*>`).ReplaceAllString(caption.Text, "")
+		text := stripTags(caption.Text)
 		builder.WriteString(text)
 		builder.WriteString(" ")
 	}
 	
 	return strings.TrimSpace(builder.String())
 }
+
+// PlainText returns the caption text with markup tags removed
+func (c Caption) PlainText() string {
+	return stripTags(c.Text)
+}
+
+// stripTags removes HTML-style markup such as <i> or <c.yellow> from caption text
+func stripTags(text string) string {
+	return tagPattern.ReplaceAllString(text, "")
+}
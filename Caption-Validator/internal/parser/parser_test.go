@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"bufio"
+	"io"
 	"strings"
 	"testing"
 )
@@ -124,3 +126,109 @@ This is the third caption.
 		t.Errorf("Third caption timing incorrect: got %f-->%f", captions[2].StartTime, captions[2].EndTime)
 	}
 }
+
+func TestParseCaptionsInvalidTimes(t *testing.T) {
+	tests := []struct {
+		format string
+		input  string
+	}{
+		{FormatSRT, "1\nNaN:00:01,000 --> 00:00:02,000\nHello\n"},
+		{FormatSRT, "1\n00:00:01,000 --> Inf:00:02,000\nHello\n"},
+		{FormatWebVTT, "WEBVTT\n\nNaN:01.000 --> 00:02.000\nHello\n"},
+	}
+	for _, tt := range tests {
+		if captions, err := ParseCaptions(strings.NewReader(tt.input), tt.format); err == nil {
+			t.Errorf("Expected an error for %q, got %+v", tt.input, captions)
+		}
+	}
+}
+
+func TestCaptionLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(r io.Reader) ([]Caption, error)
+		input string
+		want  []int
+	}{
+		{"WebVTT", parseWebVTT, "WEBVTT\nKind: captions\n\n1\n00:00:01.000 --> 00:00:02.000\nOne\n\n00:00:03.000 --> 00:00:04.000\nTwo\n", []int{5, 8}},
+		{"Chunked WebVTT", parseChunkedWebVTT, "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nOne\nLine two\n\n00:00:03.000 --> 00:00:04.000\nTwo\n", []int{3, 7}},
+		{"SRT", parseSRT, "1\n00:00:01,000 --> 00:00:02,000\nOne\n\n2\n00:00:03,000 --> 00:00:04,000\nTwo\n", []int{2, 6}},
+		{"Chunked SRT", parseChunkedSRT, "\n1\n00:00:01,000 --> 00:00:02,000\nOne\n\n00:00:03,000 --> 00:00:04,000\nTwo\n", []int{3, 6}},
+		{"TTML", parseTTML, "<?xml version=\"1.0\"?>\n<tt xmlns=\"http://www.w3.org/ns/ttml\">\n<body><div>\n<p begin=\"1s\" end=\"2s\">One</p>\n\n<p begin=\"3s\"\n   end=\"4s\">Two</p>\n</div></body>\n</tt>\n", []int{4, 6}},
+		{"SCC", parseSCC, "Scenarist_SCC V1.0\n\n00:00:00:00\t9420 9420 94ae 94ae c8e9 942f 942f\n\n00:00:02:00\t942c 942c\n\n00:00:03:00\t9420 9420 94ae 94ae c8e9 942f 942f\n", []int{3, 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captions, err := tt.parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("parse returned error: %v", err)
+			}
+			if len(captions) != len(tt.want) {
+				t.Fatalf("Expected %d captions, got %+v", len(tt.want), captions)
+			}
+			for i, want := range tt.want {
+				if captions[i].Line != want {
+					t.Errorf("Caption %d is on line %d, want %d", i+1, captions[i].Line, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCaptionsReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		format string
+	}{
+		{"WebVTT", "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nOne\n", FormatWebVTT},
+		{"SRT", "1\n00:00:01,000 --> 00:00:02,000\nOne\n", FormatSRT},
+		{"TTML", "<?xml version=\"1.0\"?>\n<tt xmlns=\"http://www.w3.org/ns/ttml\"><body><div><p begin=\"1s\" end=\"2s\">One</p></div></body></tt>\n", FormatTTML},
+		{"SCC", "Scenarist_SCC V1.0\n\n00:00:01:00\t9420 9420 94ae 94ae cfee e580 942f 942f\n\n00:00:02:00\t942c 942c\n", FormatSCC},
+		{"Unsupported", "Just some notes\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := DetectFormat(strings.NewReader(tt.input))
+			if tt.format == "" {
+				if err != ErrUnsupportedFormat {
+					t.Errorf("DetectFormat() error = %v, want ErrUnsupportedFormat", err)
+				}
+				if _, _, err := ParseCaptionsReader(strings.NewReader(tt.input)); err != ErrUnsupportedFormat {
+					t.Errorf("ParseCaptionsReader() error = %v, want ErrUnsupportedFormat", err)
+				}
+				return
+			}
+			if err != nil || format != tt.format {
+				t.Errorf("DetectFormat() = %q, %v, want %q", format, err, tt.format)
+			}
+
+			captions, format, err := ParseCaptionsReader(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ParseCaptionsReader returned error: %v", err)
+			}
+			if format != tt.format || len(captions) != 1 || captions[0].PlainText() != "One" {
+				t.Errorf("ParseCaptionsReader() = %+v, %q", captions, format)
+			}
+		})
+	}
+}
+
+func TestPeekFormat(t *testing.T) {
+	input := "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nOne\n"
+
+	// A buffer smaller than the detection window still detects what fits
+	br := bufio.NewReaderSize(strings.NewReader(input), 16)
+	format, err := PeekFormat(br)
+	if err != nil || format != FormatWebVTT {
+		t.Fatalf("PeekFormat() = %q, %v, want %q", format, err, FormatWebVTT)
+	}
+
+	// Peeking leaves the reader at the start
+	captions, err := ParseCaptions(br, format)
+	if err != nil || len(captions) != 1 || captions[0].Text != "One" {
+		t.Errorf("ParseCaptions() after PeekFormat = %+v, %v", captions, err)
+	}
+}
@@ -0,0 +1,440 @@
+package parser
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SCC files carry CEA-608 byte pairs at the NTSC frame rate, one pair per frame
+const (
+	sccHeader        = "Scenarist_SCC V1.0"
+	sccFrameDuration = 1001.0 / 30000.0
+	sccRows          = 15
+	sccColumns       = 32
+)
+
+// CEA-608 caption modes
+const (
+	sccModePopOn = iota
+	sccModeRollUp
+	sccModePaintOn
+	sccModeText
+)
+
+var sccTimecodePattern = regexp.MustCompile(`^(\d{2}):(\d{2}):(\d{2})([:;.,])(\d{2})$`)
+
+// sccStandardChars lists the characters of the basic 608 set that differ from ASCII
+var sccStandardChars = map[byte]rune{
+	0x2A: 'á', 0x5C: 'é', 0x5E: 'í', 0x5F: 'ó', 0x60: 'ú',
+	0x7B: 'ç', 0x7C: '÷', 0x7D: 'Ñ', 0x7E: 'ñ', 0x7F: '█',
+}
+
+// sccSpecialChars is indexed by the second byte of a 0x11 special character pair minus 0x30
+var sccSpecialChars = []rune("®°½¿™¢£♪à èâêîôû")
+
+// sccExtendedChars is indexed by the second byte of a 0x12/0x13 extended character pair minus 0x20
+var sccExtendedChars = map[byte][]rune{
+	0x12: []rune("ÁÉÓÚÜü‘¡*’─©℠•“”ÀÂÇÈÊËëÎÏïÔÙùÛ«»"),
+	0x13: []rune("ÃãÍÌìÒòÕõ{}\\^_|~ÄäÖöß¥¤│ÅåØø┌┐└┘"),
+}
+
+// sccPACRows maps the first byte of a preamble address code to its two rows
+// (for second bytes 0x40-0x5F and 0x60-0x7F respectively)
+var sccPACRows = map[byte][2]int{
+	0x11: {1, 2}, 0x12: {3, 4}, 0x15: {5, 6}, 0x16: {7, 8},
+	0x17: {9, 10}, 0x10: {11, 11}, 0x13: {12, 13}, 0x14: {14, 15},
+}
+
+// sccMemory is one 608 caption memory (displayed or non-displayed)
+type sccMemory [sccRows][sccColumns]rune
+
+// text returns the visible rows of the memory, one line per row
+func (m *sccMemory) text() string {
+	var lines []string
+	for row := 0; row < sccRows; row++ {
+		line := strings.TrimSpace(strings.Map(func(r rune) rune {
+			if r == 0 {
+				return ' '
+			}
+			return r
+		}, string(m[row][:])))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sccDecoder interprets the CC1 channel of a CEA-608 stream and records
+// every interval during which the displayed memory shows text. Field 2
+// data (CC3 and CC4) interleaved in the stream is skipped.
+type sccDecoder struct {
+	mode         int
+	rollUpRows   int
+	displayed    sccMemory
+	nonDisplayed sccMemory
+	row          int // 1-based
+	column       int // 0-based
+	channel      int
+	field        int // 1 or 2, as set by the last miscellaneous control code
+	lastControl  [2]byte
+
+	dirty      bool
+	dirtySince float64
+	dirtyLine  int
+
+	line      int // line of the file being decoded
+	captions  []Caption
+	open      bool
+	openText  string
+	openStart float64
+	openLine  int
+	lastTime  float64
+}
+
+// parseSCC parses a Scenarist SCC file, decoding the CEA-608 data so that
+// each caption spans the time it is actually visible on screen
+func parseSCC(r io.Reader) ([]Caption, error) {
+	scanner := bufio.NewScanner(r)
+
+	if !scanner.Scan() {
+		return nil, errors.New("empty file")
+	}
+	if !strings.HasPrefix(strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "\ufeff"), sccHeader) {
+		return nil, errors.New("missing Scenarist_SCC header")
+	}
+
+	decoder := &sccDecoder{mode: sccModePopOn, row: sccRows, channel: 1, field: 1, line: 1}
+	nextFrame := 0
+
+	for scanner.Scan() {
+		decoder.line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		frame, err := parseSCCTimecode(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		// A decoder can only process one pair per frame, so a burst that runs
+		// past the next timecode delays that line's data
+		if frame < nextFrame {
+			frame = nextFrame
+		}
+
+		for i, word := range fields[1:] {
+			if len(word) != 4 {
+				return nil, fmt.Errorf("invalid SCC byte pair: %q", word)
+			}
+			value, err := strconv.ParseUint(word, 16, 16)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SCC byte pair: %q", word)
+			}
+			decoder.decodePair(byte(value>>8)&0x7F, byte(value)&0x7F, float64(frame+i)*sccFrameDuration)
+		}
+
+		nextFrame = frame + len(fields) - 1
+		decoder.flush()
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return decoder.finish(), nil
+}
+
+// parseSCCTimecode converts an SMPTE timecode to a frame number at 29.97 fps.
+// A ';' (or '.', ',') before the frames field marks drop-frame timecode.
+func parseSCCTimecode(timecode string) (int, error) {
+	m := sccTimecodePattern.FindStringSubmatch(timecode)
+	if m == nil {
+		return 0, fmt.Errorf("invalid SCC timecode: %q", timecode)
+	}
+
+	hours, _ := strconv.Atoi(m[1])
+	minutes, _ := strconv.Atoi(m[2])
+	seconds, _ := strconv.Atoi(m[3])
+	frames, _ := strconv.Atoi(m[5])
+
+	frame := (hours*3600+minutes*60+seconds)*30 + frames
+	if m[4] != ":" {
+		// Drop-frame skips frame numbers 0 and 1 every minute except each tenth minute
+		totalMinutes := hours*60 + minutes
+		frame -= 2 * (totalMinutes - totalMinutes/10)
+	}
+
+	return frame, nil
+}
+
+// decodePair interprets a single parity-stripped byte pair received at time t
+func (d *sccDecoder) decodePair(b1, b2 byte, t float64) {
+	d.lastTime = t
+
+	// Padding
+	if b1 == 0 && b2 == 0 {
+		d.lastControl = [2]byte{}
+		return
+	}
+
+	// Control codes occupy 0x10-0x1F in the first byte and are usually sent twice
+	if b1 >= 0x10 && b1 <= 0x1F {
+		pair := [2]byte{b1, b2}
+		if pair == d.lastControl {
+			d.lastControl = [2]byte{}
+			return
+		}
+		d.lastControl = pair
+
+		d.channel = 1
+		if b1&0x08 != 0 {
+			d.channel = 2
+		}
+		// Miscellaneous control codes start with 0x14 in field 1 (CC1, CC2)
+		// and 0x15 in field 2 (CC3, CC4). Other codes and text look the same
+		// in both fields, so they belong to the field of the last one.
+		if b2 >= 0x20 && b2 <= 0x2F {
+			switch b1 &^ 0x08 {
+			case 0x14:
+				d.field = 1
+			case 0x15:
+				d.field = 2
+			}
+		}
+		if d.channel != 1 || d.field != 1 {
+			return
+		}
+		d.decodeControl(b1&^0x08, b2, t)
+		return
+	}
+	d.lastControl = [2]byte{}
+
+	// 0x01-0x0F carry XDS data, which is not caption text
+	if b1 < 0x20 || d.channel != 1 || d.field != 1 || d.mode == sccModeText {
+		return
+	}
+	d.writeChar(sccStandardChar(b1), t)
+	if b2 >= 0x20 {
+		d.writeChar(sccStandardChar(b2), t)
+	}
+}
+
+// decodeControl handles a channel 1 control code
+func (d *sccDecoder) decodeControl(b1, b2 byte, t float64) {
+	switch {
+	case b1 == 0x14 && b2 >= 0x20 && b2 <= 0x2F:
+		d.decodeMiscControl(b2, t)
+
+	case b1 == 0x17 && b2 >= 0x21 && b2 <= 0x23:
+		// Tab offsets
+		d.column = min(d.column+int(b2-0x20), sccColumns-1)
+
+	case b1 == 0x11 && b2 >= 0x30 && b2 <= 0x3F:
+		d.writeChar(sccSpecialChars[b2-0x30], t)
+
+	case b1 == 0x11 && b2 >= 0x20 && b2 <= 0x2F:
+		// Mid-row style codes are displayed as a space
+		d.writeChar(' ', t)
+
+	case (b1 == 0x12 || b1 == 0x13) && b2 >= 0x20 && b2 <= 0x3F:
+		// Extended characters replace the standard fallback sent just before them
+		d.backspace(t)
+		d.writeChar(sccExtendedChars[b1][b2-0x20], t)
+
+	case b2 >= 0x40 && b2 <= 0x7F:
+		rows, ok := sccPACRows[b1]
+		if !ok {
+			return
+		}
+		row := rows[0]
+		if b2 >= 0x60 {
+			row = rows[1]
+		}
+		d.moveTo(row, t)
+		// Indent PACs encode the column in steps of four
+		d.column = 0
+		if attr := b2 & 0x1F; attr >= 0x10 {
+			d.column = int((attr&0x0E)>>1) * 4
+		}
+	}
+}
+
+// decodeMiscControl handles the 0x14 0x20-0x2F miscellaneous control codes
+func (d *sccDecoder) decodeMiscControl(code byte, t float64) {
+	switch code {
+	case 0x20: // RCL: resume caption loading
+		d.mode = sccModePopOn
+	case 0x21: // BS: backspace
+		d.backspace(t)
+	case 0x24: // DER: delete to end of row
+		memory := d.target(t)
+		for col := d.column; col < sccColumns; col++ {
+			memory[d.row-1][col] = 0
+		}
+	case 0x25, 0x26, 0x27: // RU2, RU3, RU4
+		if d.mode != sccModeRollUp {
+			d.eraseDisplayed(t)
+			d.nonDisplayed = sccMemory{}
+		}
+		d.mode = sccModeRollUp
+		d.rollUpRows = int(code-0x25) + 2
+		d.column = 0
+	case 0x29: // RDC: resume direct captioning
+		d.mode = sccModePaintOn
+	case 0x2A, 0x2B: // TR, RTD: text mode is not caption data
+		d.mode = sccModeText
+	case 0x2C: // EDM: erase displayed memory
+		d.flush()
+		d.eraseDisplayed(t)
+		d.flush()
+	case 0x2D: // CR: carriage return
+		if d.mode == sccModeRollUp {
+			d.flush()
+			d.rollUp(t)
+		}
+	case 0x2E: // ENM: erase non-displayed memory
+		d.nonDisplayed = sccMemory{}
+	case 0x2F: // EOC: end of caption, swap memories
+		d.flush()
+		d.displayed, d.nonDisplayed = d.nonDisplayed, d.displayed
+		d.mode = sccModePopOn
+		d.markDirty(t)
+		d.flush()
+	}
+}
+
+// target returns the memory that receives text in the current mode,
+// marking the display as changed when text goes straight to the screen
+func (d *sccDecoder) target(t float64) *sccMemory {
+	if d.mode == sccModePopOn {
+		return &d.nonDisplayed
+	}
+	d.markDirty(t)
+	return &d.displayed
+}
+
+// writeChar places a character at the cursor and advances it
+func (d *sccDecoder) writeChar(r rune, t float64) {
+	memory := d.target(t)
+	memory[d.row-1][d.column] = r
+	if d.column < sccColumns-1 {
+		d.column++
+	}
+}
+
+// backspace moves the cursor left and clears the character there
+func (d *sccDecoder) backspace(t float64) {
+	if d.column == 0 {
+		return
+	}
+	d.column--
+	d.target(t)[d.row-1][d.column] = 0
+}
+
+// moveTo sets the cursor row. In roll-up mode the whole window moves with it.
+func (d *sccDecoder) moveTo(row int, t float64) {
+	if d.mode == sccModeRollUp && row != d.row {
+		d.markDirty(t)
+		moved := sccMemory{}
+		for offset := 0; offset < d.rollUpRows; offset++ {
+			from, to := d.row-1-offset, row-1-offset
+			if from >= 0 && to >= 0 {
+				moved[to] = d.displayed[from]
+			}
+		}
+		d.displayed = moved
+	}
+	d.row = row
+}
+
+// rollUp scrolls the roll-up window by one row and clears the base row
+func (d *sccDecoder) rollUp(t float64) {
+	d.markDirty(t)
+	top := d.row - d.rollUpRows
+	for row := 0; row < d.row-1; row++ {
+		if row >= top {
+			d.displayed[row] = d.displayed[row+1]
+		} else {
+			d.displayed[row] = [sccColumns]rune{}
+		}
+	}
+	d.displayed[d.row-1] = [sccColumns]rune{}
+	d.column = 0
+}
+
+// eraseDisplayed clears the screen
+func (d *sccDecoder) eraseDisplayed(t float64) {
+	if d.displayed == (sccMemory{}) {
+		return
+	}
+	d.displayed = sccMemory{}
+	d.markDirty(t)
+}
+
+// markDirty records the first time the displayed memory changed since the last flush
+func (d *sccDecoder) markDirty(t float64) {
+	if !d.dirty {
+		d.dirty = true
+		d.dirtySince = t
+		d.dirtyLine = d.line
+	}
+}
+
+// flush turns pending changes to the displayed memory into caption boundaries
+func (d *sccDecoder) flush() {
+	if !d.dirty {
+		return
+	}
+	d.dirty = false
+
+	text := d.displayed.text()
+	if d.open && text == d.openText {
+		return
+	}
+
+	d.closeCaption(d.dirtySince)
+	if text != "" {
+		d.open = true
+		d.openText = text
+		d.openStart = d.dirtySince
+		d.openLine = d.dirtyLine
+	}
+}
+
+// closeCaption ends the caption currently on screen at time t
+func (d *sccDecoder) closeCaption(t float64) {
+	if !d.open {
+		return
+	}
+	d.captions = append(d.captions, Caption{
+		Index:     len(d.captions) + 1,
+		StartTime: d.openStart,
+		EndTime:   t,
+		Text:      d.openText,
+		Line:      d.openLine,
+	})
+	d.open = false
+	d.openText = ""
+}
+
+// finish closes any caption still on screen at the end of the data
+func (d *sccDecoder) finish() []Caption {
+	d.flush()
+	d.closeCaption(d.lastTime)
+	return d.captions
+}
+
+// sccStandardChar maps a byte of the basic 608 character set to a rune
+func sccStandardChar(b byte) rune {
+	if r, ok := sccStandardChars[b]; ok {
+		return r
+	}
+	return rune(b)
+}
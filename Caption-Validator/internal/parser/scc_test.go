@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSCC(t *testing.T) {
+	// Pop-on caption, then roll-up, then paint-on
+	sccContent := "Scenarist_SCC V1.0\n\n" +
+		"00:00:00:00\t9420 9420 94ae 94ae 9440 9440 c8e5 ecec ef80 94e0 94e0 f7ef f2ec 6480 942f 942f\n\n" +
+		"00:00:02:00\t942c 942c\n\n" +
+		"00:00:03:00\t9425 9425 94e0 94e0 46e9 f273 f420 ece9 6ee5\n\n" +
+		"00:00:05:00\t94ad 94ad d3e5 e3ef 6e64 20ec e96e e580\n\n" +
+		"00:00:07:00\t942c 942c\n\n" +
+		"00:00:08:00\t9429 9429 94e0 94e0 d061 e96e f480 9137 9137\n\n" +
+		"00:00:09:00\t942c 942c\n"
+
+	captions, err := parseSCC(strings.NewReader(sccContent))
+	if err != nil {
+		t.Fatalf("parseSCC returned error: %v", err)
+	}
+
+	tests := []struct {
+		startFrame int
+		endFrame   int
+		text       string
+	}{
+		// EOC is the 15th pair of the first line, so the caption appears at frame 14
+		{14, 60, "Hello\nworld"},
+		{94, 150, "First line"},
+		{150, 210, "First line\nSecond line"},
+		{244, 270, "Paint♪"},
+	}
+
+	if len(captions) != len(tests) {
+		t.Fatalf("Expected %d captions, got %d: %+v", len(tests), len(captions), captions)
+	}
+
+	for i, tt := range tests {
+		wantStart := float64(tt.startFrame) * sccFrameDuration
+		wantEnd := float64(tt.endFrame) * sccFrameDuration
+		if math.Abs(captions[i].StartTime-wantStart) > 1e-9 || math.Abs(captions[i].EndTime-wantEnd) > 1e-9 {
+			t.Errorf("Caption %d timing incorrect: got %f-->%f, want %f-->%f",
+				i+1, captions[i].StartTime, captions[i].EndTime, wantStart, wantEnd)
+		}
+		if captions[i].Text != tt.text {
+			t.Errorf("Caption %d text incorrect: got %q, want %q", i+1, captions[i].Text, tt.text)
+		}
+	}
+}
+
+func TestParseSCCSkipsField2(t *testing.T) {
+	// A CC3 caption "Bad" (0x15 miscellaneous codes) between CC1's "Hello"
+	// appearing and being erased must not reach the CC1 captions
+	sccContent := "Scenarist_SCC V1.0\n\n" +
+		"00:00:00:00\t9420 9420 9440 9440 c8e5 ecec ef80 942f 942f\n\n" +
+		"00:00:01:00\t1520 1520 9440 9440 c261 6480 152f 152f\n\n" +
+		"00:00:02:00\t942c 942c\n"
+
+	captions, err := parseSCC(strings.NewReader(sccContent))
+	if err != nil {
+		t.Fatalf("parseSCC returned error: %v", err)
+	}
+	if len(captions) != 1 || captions[0].Text != "Hello" || math.Abs(captions[0].EndTime-60*sccFrameDuration) > 1e-9 {
+		t.Errorf("Expected only the CC1 caption shown until frame 60, got %+v", captions)
+	}
+}
+
+func TestParseSCCExtendedCharacters(t *testing.T) {
+	// "Cafe" followed by the extended character É, which replaces the fallback "e"
+	sccContent := "Scenarist_SCC V1.0\n\n" +
+		"00:00:01:00\t9420 9420 94e0 94e0 4361 e6e5 92a1 92a1 942f 942f\n\n" +
+		"00:00:03:00\t942c 942c\n"
+
+	captions, err := parseSCC(strings.NewReader(sccContent))
+	if err != nil {
+		t.Fatalf("parseSCC returned error: %v", err)
+	}
+	if len(captions) != 1 || captions[0].Text != "CafÉ" {
+		t.Errorf("Unexpected captions: %+v", captions)
+	}
+}
+
+func TestParseSCCTimecode(t *testing.T) {
+	tests := []struct {
+		input       string
+		want        int
+		expectError bool
+	}{
+		{"00:00:01:15", 45, false},
+		{"00:01:00:02", 1802, false},
+		{"00:01:00;02", 1800, false},
+		{"00:10:00;00", 17982, false},
+		{"01:00:00;00", 107892, false},
+		{"1:00:00", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSCCTimecode(tt.input)
+		if (err != nil) != tt.expectError {
+			t.Errorf("parseSCCTimecode(%q) error = %v, expectError %v", tt.input, err, tt.expectError)
+			continue
+		}
+		if !tt.expectError && got != tt.want {
+			t.Errorf("parseSCCTimecode(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDetectSCCFormat(t *testing.T) {
+	sccFile := filepath.Join(t.TempDir(), "episode.scc")
+	sccContent := "Scenarist_SCC V1.0\n\n00:00:00:00\t9420 9420 94e0 94e0 c8e5 ecec ef80 942f 942f\n\n00:00:02:00\t942c 942c\n"
+	if err := os.WriteFile(sccFile, []byte(sccContent), 0644); err != nil {
+		t.Fatalf("Failed to create test SCC file: %v", err)
+	}
+
+	captions, format, err := ParseCaptionsFile(sccFile)
+	if err != nil {
+		t.Fatalf("ParseCaptionsFile returned error: %v", err)
+	}
+	if format != FormatSCC {
+		t.Errorf("Expected format %s, got %s", FormatSCC, format)
+	}
+	if len(captions) != 1 || captions[0].Text != "Hello" {
+		t.Errorf("Unexpected captions: %+v", captions)
+	}
+}
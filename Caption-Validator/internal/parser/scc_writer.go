@@ -0,0 +1,257 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// sccMaxLines is the number of rows used at the bottom of the screen for pop-on captions
+const sccMaxLines = 4
+
+// sccExtendedFallbacks holds the basic character sent before each extended
+// character, for decoders that do not support the extended set
+var sccExtendedFallbacks = map[byte]string{
+	0x12: `AEOUUu'!.'-cs.""AACEEEeIIiOUuU""`,
+	0x13: `AaIIiOoOo()/ -!-AaOosYc!AaOo++++`,
+}
+
+// sccEncoded is how a rune is sent: either one basic character byte, or a
+// two-byte special/extended character code preceded by an optional fallback
+type sccEncoded struct {
+	basic    byte
+	code     [2]byte
+	fallback byte
+}
+
+// sccEncodeTable maps runes to their CEA-608 encoding. It is built from the
+// decoding tables so the two directions cannot drift apart.
+var sccEncodeTable = buildSCCEncodeTable()
+
+func buildSCCEncodeTable() map[rune]sccEncoded {
+	table := make(map[rune]sccEncoded)
+
+	for b := byte(0x20); b < 0x80; b++ {
+		table[sccStandardChar(b)] = sccEncoded{basic: b}
+	}
+	for i, r := range sccSpecialChars {
+		if r == ' ' {
+			continue
+		}
+		table[r] = sccEncoded{code: [2]byte{0x11, 0x30 + byte(i)}}
+	}
+	for b1, chars := range sccExtendedChars {
+		fallbacks := sccExtendedFallbacks[b1]
+		for i, r := range chars {
+			if _, ok := table[r]; ok {
+				continue
+			}
+			table[r] = sccEncoded{code: [2]byte{b1, 0x20 + byte(i)}, fallback: fallbacks[i]}
+		}
+	}
+
+	return table
+}
+
+// IsCEA608Character reports whether r is in the CEA-608 character set, i.e.
+// whether it survives conversion to SCC unchanged
+func IsCEA608Character(r rune) bool {
+	_, ok := sccEncodeTable[r]
+	return ok
+}
+
+// sccEvent is a burst of byte pairs that should start at a given frame
+type sccEvent struct {
+	frame int
+	words []string
+}
+
+// writeSCC writes captions as pop-on CEA-608 data in a Scenarist SCC file.
+// Each caption is loaded into non-displayed memory ahead of time so that its
+// EOC lands on the start frame, and erased with EDM at its end frame unless
+// the next caption replaces it. Characters outside the CEA-608 set are
+// written as spaces, and lines beyond the 32-column limit are wrapped.
+func writeSCC(w io.Writer, captions []Caption) error {
+	var events []sccEvent
+
+	for i, caption := range captions {
+		startFrame := sccFrame(caption.StartTime)
+		endFrame := sccFrame(caption.EndTime)
+
+		load := sccLoadWords(caption.Text)
+		loadFrame := startFrame - len(load)
+		if i > 0 {
+			// Prefer to finish loading before the previous caption is erased, but
+			// never start before the previous EOC has been sent
+			loadFrame = min(startFrame, sccFrame(captions[i-1].EndTime)) - len(load)
+			loadFrame = max(loadFrame, sccFrame(captions[i-1].StartTime)+2)
+		}
+
+		events = append(events,
+			sccEvent{frame: max(loadFrame, 0), words: load},
+			sccEvent{frame: startFrame, words: sccControl(0x14, 0x2F)}, // EOC
+		)
+
+		// Only erase when the screen would otherwise keep showing this caption
+		if i == len(captions)-1 || sccFrame(captions[i+1].StartTime) > endFrame {
+			events = append(events, sccEvent{frame: endFrame, words: sccControl(0x14, 0x2C)}) // EDM
+		}
+	}
+
+	sort.SliceStable(events, func(a, b int) bool {
+		return events[a].frame < events[b].frame
+	})
+
+	if _, err := io.WriteString(w, sccHeader+"\n\n"); err != nil {
+		return err
+	}
+
+	nextFrame := 0
+	for _, event := range events {
+		frame := max(event.frame, nextFrame)
+		if _, err := fmt.Fprintf(w, "%s\t%s\n\n", formatSCCTimecode(frame), strings.Join(event.words, " ")); err != nil {
+			return err
+		}
+		nextFrame = frame + len(event.words)
+	}
+
+	return nil
+}
+
+// sccLoadWords builds the byte pairs that load a caption into non-displayed memory
+func sccLoadWords(text string) []string {
+	words := append(sccControl(0x14, 0x20), sccControl(0x14, 0x2E)...) // RCL, ENM
+
+	lines := sccWrapLines(cueText(plainCueText(text)))
+	firstRow := sccRows - len(lines) + 1
+	for i, line := range lines {
+		words = append(words, sccPAC(firstRow+i)...)
+		words = append(words, sccTextWords(line)...)
+	}
+
+	return words
+}
+
+// sccWrapLines splits caption text into at most sccMaxLines rows of 32 columns
+func sccWrapLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		var current []rune
+		for _, word := range strings.Fields(line) {
+			wordRunes := []rune(word)
+			if len(current) > 0 && len(current)+1+len(wordRunes) > sccColumns {
+				lines = append(lines, string(current))
+				current = nil
+			}
+			if len(current) > 0 {
+				current = append(current, ' ')
+			}
+			current = append(current, wordRunes...)
+			for len(current) > sccColumns {
+				lines = append(lines, string(current[:sccColumns]))
+				current = current[sccColumns:]
+			}
+		}
+		if len(current) > 0 {
+			lines = append(lines, string(current))
+		}
+	}
+
+	// Keep the last rows, which is what a viewer would be reading at the cue end
+	if len(lines) > sccMaxLines {
+		lines = lines[len(lines)-sccMaxLines:]
+	}
+	return lines
+}
+
+// sccTextWords encodes a line of text as byte pairs
+func sccTextWords(line string) []string {
+	var words []string
+	var pending []byte
+
+	flushBasic := func() {
+		if len(pending)%2 == 1 {
+			pending = append(pending, 0x00)
+		}
+		for i := 0; i < len(pending); i += 2 {
+			words = append(words, sccWord(pending[i], pending[i+1]))
+		}
+		pending = nil
+	}
+
+	for _, r := range line {
+		encoded, ok := sccEncodeTable[r]
+		if !ok {
+			encoded = sccEncoded{basic: ' '}
+		}
+		if encoded.basic != 0 {
+			pending = append(pending, encoded.basic)
+			continue
+		}
+		if encoded.fallback != 0 {
+			pending = append(pending, encoded.fallback)
+		}
+		flushBasic()
+		words = append(words, sccControl(encoded.code[0], encoded.code[1])...)
+	}
+	flushBasic()
+
+	return words
+}
+
+// sccPAC returns the preamble address code placing the cursor at column 0 of a row
+func sccPAC(row int) []string {
+	for b1, rows := range sccPACRows {
+		if rows[0] == row {
+			return sccControl(b1, 0x40)
+		}
+		if rows[1] == row {
+			return sccControl(b1, 0x60)
+		}
+	}
+	return sccControl(0x14, 0x60)
+}
+
+// sccControl returns a control code, doubled as is customary for reliability
+func sccControl(b1, b2 byte) []string {
+	word := sccWord(b1, b2)
+	return []string{word, word}
+}
+
+// sccWord formats two bytes as a hex word with odd parity applied
+func sccWord(b1, b2 byte) string {
+	return fmt.Sprintf("%02x%02x", sccParity(b1), sccParity(b2))
+}
+
+// sccParity sets the high bit so that the byte has odd parity
+func sccParity(b byte) byte {
+	b &= 0x7F
+	ones := 0
+	for v := b; v != 0; v >>= 1 {
+		ones += int(v & 1)
+	}
+	if ones%2 == 0 {
+		b |= 0x80
+	}
+	return b
+}
+
+// sccFrame converts seconds to the nearest 29.97 fps frame number
+func sccFrame(seconds float64) int {
+	return int(math.Round(math.Max(seconds, 0) / sccFrameDuration))
+}
+
+// formatSCCTimecode converts a frame number to drop-frame SMPTE timecode
+func formatSCCTimecode(frame int) string {
+	tenMinutes := frame / 17982
+	remainder := frame % 17982
+	frame += 18 * tenMinutes
+	if remainder > 1 {
+		frame += 2 * ((remainder - 2) / 1798)
+	}
+
+	return fmt.Sprintf("%02d:%02d:%02d;%02d",
+		frame/108000, (frame/1800)%60, (frame/30)%60, frame%30)
+}
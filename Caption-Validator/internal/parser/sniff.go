@@ -0,0 +1,226 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// FormatASS is Advanced SubStation Alpha (and SSA). It is recognised so such
+// files are reported for what they are, but it cannot be parsed.
+const FormatASS = "ASS"
+
+// ErrAmbiguousFormat is returned (wrapped) when the content matches more than
+// one format about equally well. Callers should name the format instead.
+var ErrAmbiguousFormat = errors.New("ambiguous caption format")
+
+const (
+	// minConfidence is the lowest score a format is accepted with
+	minConfidence = 0.5
+	// ambiguityMargin is how far the best format must score above the
+	// runner-up for the match not to be ambiguous; the small allowance keeps
+	// floating point error in the sums from tipping a match either way
+	ambiguityMargin = 0.2 - 1e-9
+	// hintedConfidence caps the score of a match without its format's
+	// signature, so only a signature gives full confidence
+	hintedConfidence = 0.95
+)
+
+// Signatures and cue patterns the sniffers look for
+var (
+	utf8BOM           = []byte("\xef\xbb\xbf")
+	webVTTTimingLine  = regexp.MustCompile(`(?m)^(?:\d{2,}:)?\d{2}:\d{2}\.\d{3}[ \t]+-->[ \t]+(?:\d{2,}:)?\d{2}:\d{2}\.\d{3}`)
+	srtFirstCue       = regexp.MustCompile(`^\s*\d+[ \t]*\r?\n[ \t]*\d{1,2}:\d{2}:\d{2}[,.]\d{1,3}[ \t]*-->`)
+	srtTimingLine     = regexp.MustCompile(`(?m)^[ \t]*\d{1,2}:\d{2}:\d{2}[,.]\d{1,3}[ \t]*-->[ \t]*\d{1,2}:\d{2}:\d{2}[,.]\d{1,3}`)
+	sccTimecodeLine   = regexp.MustCompile(`(?m)^\d{2}:\d{2}:\d{2}[:;]\d{2}\t[0-9a-fA-F]{4}(?: [0-9a-fA-F]{4})*\s*$`)
+	assSectionPattern = regexp.MustCompile(`(?m)^(?:\[V4\+? Styles\]|\[Events\]|Dialogue:)`)
+)
+
+// sniffer scores how well the start of a file matches one format, from 0 to
+// 1. ext is the lower-cased file extension, or "" when there is no name.
+type sniffer struct {
+	format string
+	score  func(header []byte, ext string) float64
+}
+
+// sniffers holds every format detection knows about
+var sniffers = []sniffer{
+	{FormatWebVTT, sniffWebVTT},
+	{FormatSRT, sniffSRT},
+	{FormatTTML, sniffTTML},
+	{FormatSCC, sniffSCC},
+	{FormatASS, sniffASS},
+}
+
+// Candidate is one format a file might be in, with its score
+type Candidate struct {
+	Format     string  `json:"format"`
+	Confidence float64 `json:"confidence"` // 0 to 1
+}
+
+// Detection is the result of sniffing a caption file's format
+type Detection struct {
+	Format     string      `json:"format"`     // best-scoring format, "" if nothing matched
+	Confidence float64     `json:"confidence"` // score of Format, 0 to 1
+	Candidates []Candidate `json:"candidates"` // every format that matched at all, best first
+}
+
+// Sniff scores the start of a file against every known format. name is the
+// file's name, whose extension is used as a hint, or "" if it has none.
+func Sniff(header []byte, name string) Detection {
+	header = bytes.TrimPrefix(header, utf8BOM)
+	ext := strings.ToLower(filepath.Ext(name))
+
+	var detection Detection
+	for _, s := range sniffers {
+		if score := s.score(header, ext); score > 0 {
+			detection.Candidates = append(detection.Candidates, Candidate{Format: s.format, Confidence: score})
+		}
+	}
+	sort.SliceStable(detection.Candidates, func(i, j int) bool {
+		return detection.Candidates[i].Confidence > detection.Candidates[j].Confidence
+	})
+	if len(detection.Candidates) > 0 {
+		detection.Format = detection.Candidates[0].Format
+		detection.Confidence = detection.Candidates[0].Confidence
+	}
+	return detection
+}
+
+// SniffFile sniffs the format of a caption file from its first 1KB and its name
+func SniffFile(filePath string) (Detection, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return Detection{}, err
+	}
+	defer file.Close()
+
+	header := make([]byte, detectHeaderSize)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return Detection{}, err
+	}
+	return Sniff(header[:n], filePath), nil
+}
+
+// SniffReader sniffs the format of the captions at the start of a buffered
+// reader without consuming them, so the same reader can then be parsed
+func SniffReader(br *bufio.Reader, name string) (Detection, error) {
+	header, err := br.Peek(detectHeaderSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return Detection{}, err
+	}
+	return Sniff(header, name), nil
+}
+
+// Result returns the detected format if it can be relied on. It returns
+// ErrUnsupportedFormat when no format scored at least minConfidence or the
+// best match cannot be parsed, and wraps ErrAmbiguousFormat when another
+// format scored nearly as well.
+func (d Detection) Result() (string, error) {
+	if d.Confidence < minConfidence {
+		return "", ErrUnsupportedFormat
+	}
+	if len(d.Candidates) > 1 {
+		runnerUp := d.Candidates[1]
+		if runnerUp.Confidence >= minConfidence && d.Confidence-runnerUp.Confidence < ambiguityMargin {
+			return "", fmt.Errorf("%w: %s", ErrAmbiguousFormat, d)
+		}
+	}
+	if d.Format == FormatASS {
+		return "", fmt.Errorf("%w: %s files cannot be parsed", ErrUnsupportedFormat, d.Format)
+	}
+	return d.Format, nil
+}
+
+// String lists the candidates with their confidence, e.g. "SRT (0.80), WebVTT (0.70)"
+func (d Detection) String() string {
+	parts := make([]string, len(d.Candidates))
+	for i, candidate := range d.Candidates {
+		parts[i] = fmt.Sprintf("%s (%.2f)", candidate.Format, candidate.Confidence)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// hinted adds the file extension hint to a content score. The extension
+// alone scores below minConfidence, so it never decides the format by itself,
+// but with any of its format's cue timing lines it does.
+func hinted(score float64, ext string, extensions ...string) float64 {
+	for _, e := range extensions {
+		if ext == e {
+			score += 0.3
+			break
+		}
+	}
+	return min(score, hintedConfidence)
+}
+
+func sniffWebVTT(header []byte, ext string) float64 {
+	// The signature is "WEBVTT" alone on the first line or followed by a space or tab
+	if rest, ok := bytes.CutPrefix(header, []byte("WEBVTT")); ok &&
+		(len(rest) == 0 || strings.ContainsRune(" \t\r\n", rune(rest[0]))) {
+		return 1
+	}
+
+	var score float64
+	if bytes.Contains(header, []byte("WEBVTT")) {
+		score += 0.6
+	}
+	if webVTTTimingLine.Match(header) {
+		score += 0.4
+	}
+	return hinted(score, ext, ".vtt", ".webvtt")
+}
+
+// sniffSRT accepts the timing lines the SRT parser does: one-digit hours, a
+// period for the comma and indented lines are all common in the wild
+func sniffSRT(header []byte, ext string) float64 {
+	var score float64
+	switch {
+	case srtFirstCue.Match(header):
+		score = 0.9
+	case srtTimingLine.Match(header):
+		score = 0.5
+	}
+	return hinted(score, ext, ".srt")
+}
+
+func sniffTTML(header []byte, ext string) float64 {
+	var score float64
+	if ttmlRootPattern.Match(header) {
+		if bytes.Contains(header, []byte("http://www.w3.org/ns/ttml")) || bytes.Contains(header, []byte("ttaf1")) {
+			return 1
+		}
+		score = 0.4
+	}
+	return hinted(score, ext, ".ttml", ".dfxp")
+}
+
+func sniffSCC(header []byte, ext string) float64 {
+	if bytes.HasPrefix(header, []byte(sccHeader)) {
+		return 1
+	}
+	var score float64
+	if sccTimecodeLine.Match(header) {
+		score = 0.6
+	}
+	return hinted(score, ext, ".scc")
+}
+
+func sniffASS(header []byte, ext string) float64 {
+	if bytes.HasPrefix(bytes.TrimSpace(header), []byte("[Script Info]")) {
+		return 1
+	}
+	var score float64
+	if assSectionPattern.Match(header) {
+		score = 0.6
+	}
+	return hinted(score, ext, ".ass", ".ssa")
+}
@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		file      string
+		want      string
+		ambiguous bool
+	}{
+		{"WebVTT signature", "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nOne\n", "", FormatWebVTT, false},
+		{"WebVTT signature with BOM and title", "\xef\xbb\xbfWEBVTT - Episode 1\n\n00:00:01.000 --> 00:00:02.000\nOne\n", "", FormatWebVTT, false},
+		{"WebVTT without signature", "00:00:01.000 --> 00:00:02.000\nOne\n", "episode.vtt", FormatWebVTT, false},
+		{"SRT first cue", "1\n00:00:01,000 --> 00:00:02,000\nOne\n", "", FormatSRT, false},
+		{"SRT after blank lines", "\r\n\r\n1\r\n00:00:01,000 --> 00:00:02,000\r\nOne\r\n", "", FormatSRT, false},
+		{"SRT without index", "00:00:01,000 --> 00:00:02,000\nOne\n", "", FormatSRT, false},
+		{"SRT with a title line", "Episode 1\n\n1\n00:00:01,000 --> 00:00:02,000\nOne\n", "", FormatSRT, false},
+		{"SRT with periods and a title line", "Episode 1\n\n1\n00:00:01.000 --> 00:00:02.000\nOne\n", "episode.srt", FormatSRT, false},
+		{"SRT with short hours", "0:00:01,000 --> 0:00:02,000\nOne\n", "episode.srt", FormatSRT, false},
+		{"TTML namespace", "<?xml version=\"1.0\"?>\n<tt xmlns=\"http://www.w3.org/ns/ttml\">", "", FormatTTML, false},
+		{"TTML without namespace", "<tt>\n<body><div><p begin=\"1s\" end=\"2s\">One</p></div></body></tt>", "episode.dfxp", FormatTTML, false},
+		{"SCC signature", "Scenarist_SCC V1.0\n\n00:00:01:00\t9420 9420\n", "", FormatSCC, false},
+		{"SCC without signature", "00:00:01;00\t9420 9420 94ae 94ae\n", "", FormatSCC, false},
+		{"ASS is recognised but unsupported", "[Script Info]\nTitle: Episode 1\nScriptType: v4.00+\n", "", FormatASS, false},
+		{"SRT cue in a WebVTT file", "1\n00:00:01,000 --> 00:00:02,000\nWEBVTT is a format\n", "episode.vtt", "", true},
+		{"Extension alone", "Just some notes\n", "episode.srt", "", false},
+		{"Plain text", "Just some notes\n", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detection := Sniff([]byte(tt.header), tt.file)
+			format, err := detection.Result()
+
+			switch {
+			case tt.ambiguous:
+				if !errors.Is(err, ErrAmbiguousFormat) {
+					t.Errorf("Result() = %q, %v, want ErrAmbiguousFormat (%s)", format, err, detection)
+				}
+			case tt.want == "" || tt.want == FormatASS:
+				if !errors.Is(err, ErrUnsupportedFormat) {
+					t.Errorf("Result() = %q, %v, want ErrUnsupportedFormat (%s)", format, err, detection)
+				}
+				if detection.Format != tt.want && tt.want != "" {
+					t.Errorf("Detection.Format = %q, want %q", detection.Format, tt.want)
+				}
+			default:
+				if err != nil || format != tt.want {
+					t.Errorf("Result() = %q, %v, want %q (%s)", format, err, tt.want, detection)
+				}
+				if detection.Confidence < minConfidence || detection.Confidence > 1 {
+					t.Errorf("Confidence %v out of range", detection.Confidence)
+				}
+			}
+		})
+	}
+}
+
+func TestSniffConfidence(t *testing.T) {
+	signature := Sniff([]byte("WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nOne\n"), "")
+	hinted := Sniff([]byte("00:00:01.000 --> 00:00:02.000\nOne\n"), "episode.vtt")
+	if signature.Confidence != 1 || hinted.Confidence >= signature.Confidence {
+		t.Errorf("A signature should be more certain than a hint: %s vs %s", signature, hinted)
+	}
+	if len(hinted.Candidates) == 0 || hinted.Candidates[0].Format != FormatWebVTT {
+		t.Errorf("Expected WebVTT as the best candidate, got %s", hinted)
+	}
+}
+
+func TestDetectCaptionFormatSamples(t *testing.T) {
+	samples := map[string]string{
+		"english.vtt":                      FormatWebVTT,
+		"french.vtt":                       FormatWebVTT,
+		"spanish.vtt":                      FormatWebVTT,
+		"sample.srt":                       FormatSRT,
+		"sample_episodes/episode1.vtt":     FormatWebVTT,
+		"sample_episodes/episode2.vtt":     FormatWebVTT,
+		"sample_episodes/episode3.srt":     FormatSRT,
+		"sample_episodes/episode4.vtt":     FormatWebVTT,
+		"sample_episodes/episode5.vtt":     FormatWebVTT,
+		"sample_episodes/unsupported.txt":  "",
+		"sample_episodes/unsupported.json": "",
+		"sample_episodes/unsupported.xml":  "",
+	}
+	for name, want := range samples {
+		path := filepath.Join("..", "..", "test", name)
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("Missing sample %s: %v", name, err)
+		}
+		format, err := DetectCaptionFormat(path)
+		if want == "" {
+			if err != ErrUnsupportedFormat {
+				t.Errorf("DetectCaptionFormat(%s) = %q, %v, want ErrUnsupportedFormat", name, format, err)
+			}
+			continue
+		}
+		if err != nil || format != want {
+			t.Errorf("DetectCaptionFormat(%s) = %q, %v, want %q", name, format, err, want)
+		}
+	}
+}
+
+// TestDetectCaptionFormatSRTVariants covers SRT files the extension check of
+// earlier versions accepted, which must still be detected and parsed
+func TestDetectCaptionFormatSRTVariants(t *testing.T) {
+	variants := map[string]string{
+		"blank-lines.srt": "\n\n\n1\n00:00:01,000 --> 00:00:02,000\nOne\n",
+		"title.srt":       "Episode 1 - Pilot\n\n1\n00:00:01,000 --> 00:00:02,000\nOne\n",
+		"periods.srt":     "Episode 1 - Pilot\n1\n00:00:01.000 --> 00:00:02.000\nOne\n",
+		"indented.srt":    "1\n  00:00:01,000 --> 00:00:02,000\nOne\n",
+		"no-index.srt":    "00:00:01,000 --> 00:00:02,000\nOne\n\n00:00:03,000 --> 00:00:04,000\nTwo\n",
+		"bom.srt":         "\xef\xbb\xbf1\r\n00:00:01,000 --> 00:00:02,000\r\nOne\r\n",
+	}
+	dir := t.TempDir()
+	for name, content := range variants {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		captions, format, err := ParseCaptionsFile(path)
+		if err != nil || format != FormatSRT || len(captions) == 0 {
+			t.Errorf("ParseCaptionsFile(%s) = %d captions, %q, %v; want SRT", name, len(captions), format, err)
+		}
+	}
+}
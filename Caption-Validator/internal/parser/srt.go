@@ -15,9 +15,11 @@ func parseSRT(r io.Reader) ([]Caption, error) {
 	var currentCaption Caption
 	var textLines []string
 	parseState := 0 // 0=index, 1=timestamp, 2=text
+	lineNumber := 0
 
 	for scanner.Scan() {
 		line := scanner.Text()
+		lineNumber++
 		trimmedLine := strings.TrimSpace(line)
 		
 		// Empty line means end of a caption block (unless we're at the beginning)
@@ -43,7 +45,7 @@ func parseSRT(r io.Reader) ([]Caption, error) {
 				if strings.Contains(trimmedLine, "-->") {
 					startTime, endTime, timeErr := parseSRTTimeline(trimmedLine)
 					if timeErr == nil {
-						currentCaption = Caption{Index: len(captions) + 1, StartTime: startTime, EndTime: endTime}
+						currentCaption = Caption{Index: len(captions) + 1, StartTime: startTime, EndTime: endTime, Line: lineNumber}
 						parseState = 2 // Skip to text parsing
 						continue
 					}
@@ -68,6 +70,7 @@ func parseSRT(r io.Reader) ([]Caption, error) {
 				}
 				currentCaption.StartTime = startTime
 				currentCaption.EndTime = endTime
+				currentCaption.Line = lineNumber
 				parseState = 2
 				textLines = nil
 			} else {
@@ -0,0 +1,297 @@
+package parser
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ttmlTiming holds the document-level parameters (ttp:*) that control how
+// TTML time expressions are converted to seconds
+type ttmlTiming struct {
+	frameRate    float64
+	subFrameRate float64
+	tickRate     float64
+}
+
+// ttmlScope tracks the absolute active interval of a timed element so that
+// child elements can resolve their times relative to it
+type ttmlScope struct {
+	begin float64
+	end   float64 // math.Inf(1) when the element has no explicit end
+}
+
+var (
+	ttmlClockTimePattern  = regexp.MustCompile(`^(\d{2,}):(\d{2}):(\d{2})(?:(\.\d+)|:(\d{2,})(?:\.(\d+))?)?$`)
+	ttmlOffsetTimePattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)(h|ms|m|s|f|t)$`)
+	ttmlWhitespacePattern = regexp.MustCompile(`[ \t\r\n]+`)
+)
+
+// parseTTML parses a TTML (DFXP) document. Only parallel time containment is
+// supported, which is what virtually all caption deliveries use.
+func parseTTML(r io.Reader) ([]Caption, error) {
+	var captions []Caption
+	decoder := xml.NewDecoder(r)
+	// Some DFXP exports declare legacy encodings; treat them as UTF-8
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	timing := ttmlTiming{frameRate: 30, subFrameRate: 1, tickRate: 1}
+	scopes := []ttmlScope{{begin: 0, end: math.Inf(1)}}
+	foundRoot := false
+
+	var currentCaption Caption
+	var text strings.Builder
+	inParagraph := false
+	index := 1
+
+	for {
+		// The position before reading a token is where that token starts
+		line, _ := decoder.InputPos()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid TTML document: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "tt":
+				foundRoot = true
+				timing = parseTTMLTiming(t.Attr)
+				continue
+			case "br":
+				if inParagraph {
+					text.WriteString("\n")
+				}
+			}
+
+			// Every other element may carry timing that its children inherit
+			parent := scopes[len(scopes)-1]
+			scope, err := resolveTTMLScope(t.Attr, parent, timing)
+			if err != nil {
+				return nil, err
+			}
+			scopes = append(scopes, scope)
+
+			if t.Name.Local == "p" {
+				inParagraph = true
+				text.Reset()
+				currentCaption = Caption{StartTime: scope.begin, EndTime: scope.end, Line: line}
+			}
+
+		case xml.EndElement:
+			if t.Name.Local == "tt" {
+				continue
+			}
+			if len(scopes) > 1 {
+				scopes = scopes[:len(scopes)-1]
+			}
+
+			if t.Name.Local == "p" && inParagraph {
+				inParagraph = false
+				captionText := normalizeTTMLText(text.String())
+				if captionText == "" {
+					continue
+				}
+				// A paragraph with no resolvable end is displayed for zero time
+				if math.IsInf(currentCaption.EndTime, 1) {
+					currentCaption.EndTime = currentCaption.StartTime
+				}
+				currentCaption.Text = captionText
+				currentCaption.Index = index
+				captions = append(captions, currentCaption)
+				index++
+			}
+
+		case xml.CharData:
+			// Source newlines are just whitespace; only <br/> breaks a line
+			if inParagraph {
+				text.WriteString(ttmlWhitespacePattern.ReplaceAllString(string(t), " "))
+			}
+		}
+	}
+
+	if !foundRoot {
+		return nil, errors.New("missing TTML <tt> root element")
+	}
+
+	return captions, nil
+}
+
+// parseTTMLTiming reads the ttp:frameRate, ttp:frameRateMultiplier,
+// ttp:subFrameRate and ttp:tickRate parameters from the <tt> element
+func parseTTMLTiming(attrs []xml.Attr) ttmlTiming {
+	timing := ttmlTiming{frameRate: 30, subFrameRate: 1}
+	tickRateSet := false
+
+	for _, attr := range attrs {
+		value := strings.TrimSpace(attr.Value)
+		switch attr.Name.Local {
+		case "frameRate":
+			if rate, err := strconv.ParseFloat(value, 64); err == nil && rate > 0 {
+				timing.frameRate = rate
+			}
+		case "subFrameRate":
+			if rate, err := strconv.ParseFloat(value, 64); err == nil && rate > 0 {
+				timing.subFrameRate = rate
+			}
+		case "tickRate":
+			if rate, err := strconv.ParseFloat(value, 64); err == nil && rate > 0 {
+				timing.tickRate = rate
+				tickRateSet = true
+			}
+		}
+	}
+
+	// The multiplier is applied after the base rate is known, e.g. "1000 1001" for 29.97
+	for _, attr := range attrs {
+		if attr.Name.Local != "frameRateMultiplier" {
+			continue
+		}
+		parts := strings.Fields(attr.Value)
+		if len(parts) != 2 {
+			continue
+		}
+		numerator, err1 := strconv.ParseFloat(parts[0], 64)
+		denominator, err2 := strconv.ParseFloat(parts[1], 64)
+		if err1 == nil && err2 == nil && numerator > 0 && denominator > 0 {
+			timing.frameRate = timing.frameRate * numerator / denominator
+		}
+	}
+
+	// Per the TTML spec the tick rate defaults to frameRate * subFrameRate, or 1
+	if !tickRateSet {
+		timing.tickRate = 1
+		for _, attr := range attrs {
+			if attr.Name.Local == "frameRate" {
+				timing.tickRate = timing.frameRate * timing.subFrameRate
+				break
+			}
+		}
+	}
+
+	return timing
+}
+
+// resolveTTMLScope computes the absolute active interval of an element from
+// its begin, end and dur attributes and the interval of its parent
+func resolveTTMLScope(attrs []xml.Attr, parent ttmlScope, timing ttmlTiming) (ttmlScope, error) {
+	var beginAttr, endAttr, durAttr string
+	for _, attr := range attrs {
+		switch attr.Name.Local {
+		case "begin":
+			beginAttr = attr.Value
+		case "end":
+			endAttr = attr.Value
+		case "dur":
+			durAttr = attr.Value
+		}
+	}
+
+	scope := ttmlScope{begin: parent.begin, end: parent.end}
+
+	if beginAttr != "" {
+		begin, err := parseTTMLTime(beginAttr, timing)
+		if err != nil {
+			return ttmlScope{}, err
+		}
+		scope.begin = parent.begin + begin
+	}
+
+	end := math.Inf(1)
+	if endAttr != "" {
+		value, err := parseTTMLTime(endAttr, timing)
+		if err != nil {
+			return ttmlScope{}, err
+		}
+		end = parent.begin + value
+	}
+	if durAttr != "" {
+		value, err := parseTTMLTime(durAttr, timing)
+		if err != nil {
+			return ttmlScope{}, err
+		}
+		// When both end and dur are present the earlier one wins
+		end = math.Min(end, scope.begin+value)
+	}
+
+	// Children can never outlive their parent
+	scope.end = math.Min(end, parent.end)
+
+	return scope, nil
+}
+
+// parseTTMLTime converts a TTML time expression to seconds.
+// Supported forms are clock times ("01:02:03.500", "01:02:03:12",
+// "01:02:03:12.1") and offset times ("1.5s", "90m", "12f", "10000000t").
+func parseTTMLTime(expr string, timing ttmlTiming) (float64, error) {
+	expr = strings.TrimSpace(expr)
+
+	if m := ttmlClockTimePattern.FindStringSubmatch(expr); m != nil {
+		hours, _ := strconv.ParseFloat(m[1], 64)
+		minutes, _ := strconv.ParseFloat(m[2], 64)
+		seconds, _ := strconv.ParseFloat(m[3], 64)
+		total := hours*3600 + minutes*60 + seconds
+
+		if m[4] != "" {
+			fraction, _ := strconv.ParseFloat("0"+m[4], 64)
+			total += fraction
+		}
+		if m[5] != "" {
+			frames, _ := strconv.ParseFloat(m[5], 64)
+			if m[6] != "" {
+				subFrames, _ := strconv.ParseFloat(m[6], 64)
+				frames += subFrames / timing.subFrameRate
+			}
+			total += frames / timing.frameRate
+		}
+		return total, nil
+	}
+
+	if m := ttmlOffsetTimePattern.FindStringSubmatch(expr); m != nil {
+		count, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, err
+		}
+		switch m[2] {
+		case "h":
+			return count * 3600, nil
+		case "m":
+			return count * 60, nil
+		case "s":
+			return count, nil
+		case "ms":
+			return count / 1000, nil
+		case "f":
+			return count / timing.frameRate, nil
+		case "t":
+			return count / timing.tickRate, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid TTML time expression: %q", expr)
+}
+
+// normalizeTTMLText applies default XML whitespace handling: runs of
+// whitespace collapse to a single space and lines produced by <br/> are trimmed
+func normalizeTTMLText(raw string) string {
+	lines := strings.Split(raw, "\n")
+	var kept []string
+	for _, line := range lines {
+		line = strings.TrimSpace(ttmlWhitespacePattern.ReplaceAllString(line, " "))
+		if line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
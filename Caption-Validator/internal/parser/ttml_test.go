@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTTML(t *testing.T) {
+	ttmlContent := `<?xml version="1.0" encoding="UTF-8"?>
+<tt xmlns="http://www.w3.org/ns/ttml" xmlns:ttp="http://www.w3.org/ns/ttml#parameter" xmlns:tts="http://www.w3.org/ns/ttml#styling"
+    ttp:frameRate="25" ttp:tickRate="10000000">
+  <body>
+    <div begin="10s">
+      <p begin="00:00:01.000" end="00:00:04.000">This is the <span tts:fontStyle="italic">first</span> caption.</p>
+      <p begin="00:00:05:00" dur="100f">
+        This is the second caption.<br/>
+        It has multiple lines.
+      </p>
+      <p begin="100000000t" end="150000000t">Tick based caption.</p>
+      <p begin="1500ms" end="2.5s"><span>Offset <span>times</span></span></p>
+      <p begin="1m" end="61s">   </p>
+    </div>
+  </body>
+</tt>
+`
+
+	captions, err := parseTTML(strings.NewReader(ttmlContent))
+	if err != nil {
+		t.Fatalf("parseTTML returned error: %v", err)
+	}
+
+	if len(captions) != 4 {
+		t.Fatalf("Expected 4 captions, got %d", len(captions))
+	}
+
+	tests := []struct {
+		start float64
+		end   float64
+		text  string
+	}{
+		{11.0, 14.0, "This is the first caption."},
+		{15.0, 19.0, "This is the second caption.\nIt has multiple lines."},
+		{20.0, 25.0, "Tick based caption."},
+		{11.5, 12.5, "Offset times"},
+	}
+
+	for i, tt := range tests {
+		if math.Abs(captions[i].StartTime-tt.start) > 1e-9 || math.Abs(captions[i].EndTime-tt.end) > 1e-9 {
+			t.Errorf("Caption %d timing incorrect: got %f-->%f, want %f-->%f",
+				i+1, captions[i].StartTime, captions[i].EndTime, tt.start, tt.end)
+		}
+		if captions[i].Text != tt.text {
+			t.Errorf("Caption %d text incorrect: got %q, want %q", i+1, captions[i].Text, tt.text)
+		}
+		if captions[i].Index != i+1 {
+			t.Errorf("Caption %d index incorrect: got %d", i+1, captions[i].Index)
+		}
+	}
+}
+
+func TestParseTTMLTime(t *testing.T) {
+	ntsc := ttmlTiming{frameRate: 30 * 1000.0 / 1001.0, subFrameRate: 2, tickRate: 1}
+
+	tests := []struct {
+		name        string
+		input       string
+		timing      ttmlTiming
+		want        float64
+		expectError bool
+	}{
+		{"Clock time with fraction", "01:02:03.250", ntsc, 3723.25, false},
+		{"Clock time with frames", "00:00:01:15", ttmlTiming{frameRate: 30, subFrameRate: 1}, 1.5, false},
+		{"Clock time with sub-frames", "00:00:00:01.1", ttmlTiming{frameRate: 25, subFrameRate: 2}, 0.06, false},
+		{"NTSC frames", "30f", ntsc, 1.001, false},
+		{"Hours offset", "1.5h", ntsc, 5400, false},
+		{"Minutes offset", "2m", ntsc, 120, false},
+		{"Milliseconds offset", "250ms", ntsc, 0.25, false},
+		{"Ticks offset", "45t", ttmlTiming{frameRate: 30, subFrameRate: 1, tickRate: 90}, 0.5, false},
+		{"Invalid expression", "soon", ntsc, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTTMLTime(tt.input, tt.timing)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("parseTTMLTime(%q) error = %v, expectError %v", tt.input, err, tt.expectError)
+			}
+			if !tt.expectError && math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("parseTTMLTime(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTTMLMissingRoot(t *testing.T) {
+	_, err := parseTTML(strings.NewReader(`<transcript><p begin="1s" end="2s">Hello</p></transcript>`))
+	if err == nil {
+		t.Error("Expected error for document without <tt> root, got nil")
+	}
+}
+
+func TestDetectTTMLFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	ttmlFile := filepath.Join(tmpDir, "captions.xml")
+	ttmlContent := `<?xml version="1.0" encoding="utf-8"?>
+<tt xml:lang="en" xmlns="http://www.w3.org/2006/10/ttaf1">
+  <body><div><p begin="1s" end="3s">Hello</p></div></body>
+</tt>`
+	if err := os.WriteFile(ttmlFile, []byte(ttmlContent), 0644); err != nil {
+		t.Fatalf("Failed to create test TTML file: %v", err)
+	}
+
+	captions, format, err := ParseCaptionsFile(ttmlFile)
+	if err != nil {
+		t.Fatalf("ParseCaptionsFile returned error: %v", err)
+	}
+	if format != FormatTTML {
+		t.Errorf("Expected format %s, got %s", FormatTTML, format)
+	}
+	if len(captions) != 1 || captions[0].StartTime != 1.0 || captions[0].EndTime != 3.0 {
+		t.Errorf("Unexpected captions: %+v", captions)
+	}
+
+	// Plain XML that is not TTML must still be rejected
+	if _, err := DetectCaptionFormat("../../test/sample_episodes/unsupported.xml"); err != ErrUnsupportedFormat {
+		t.Errorf("Expected ErrUnsupportedFormat for non-TTML XML, got %v", err)
+	}
+}
@@ -22,8 +22,11 @@ func parseWebVTT(r io.Reader) ([]Caption, error) {
 		return nil, errors.New("missing WEBVTT header")
 	}
 
+	lineNumber := 1
+
 	// Skip header section until we find an empty line
 	for scanner.Scan() {
+		lineNumber++
 		if scanner.Text() == "" {
 			break
 		}
@@ -37,6 +40,7 @@ func parseWebVTT(r io.Reader) ([]Caption, error) {
 	// Parse cues
 	for scanner.Scan() {
 		line := scanner.Text()
+		lineNumber++
 
 		// Empty line indicates the end of a caption
 		if line == "" {
@@ -71,6 +75,7 @@ func parseWebVTT(r io.Reader) ([]Caption, error) {
 			currentCaption = Caption{
 				StartTime: startTime,
 				EndTime:   endTime,
+				Line:      lineNumber,
 			}
 			inCaption = true
 		} else if inCaption {
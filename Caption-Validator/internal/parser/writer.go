@@ -0,0 +1,223 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// formatNames maps user-facing format names and file extensions to formats
+var formatNames = map[string]string{
+	"vtt":    FormatWebVTT,
+	"webvtt": FormatWebVTT,
+	"srt":    FormatSRT,
+	"ttml":   FormatTTML,
+	"dfxp":   FormatTTML,
+	"scc":    FormatSCC,
+}
+
+var (
+	// markupTagPattern matches the tags writers convert: elements such as
+	// <i> or <c.yellow> and WebVTT timestamps such as <00:00:01.500>. Unlike
+	// tagPattern it leaves text like "1 < 2 > 0" alone.
+	markupTagPattern = regexp.MustCompile(`</?[A-Za-z][^<>]*>|<[\d:.]+>`)
+	// srtTagPattern matches the tags SRT players understand
+	srtTagPattern = regexp.MustCompile(`^</?(?:[ibu]|font)(?:\s[^>]*)?>$`)
+	// webvttTagPattern matches a WebVTT cue text tag at the start of the text
+	webvttTagPattern = regexp.MustCompile(`^(?:</?(?:[ibu]|c|v|lang|ruby|rt)(?:[.\s][^<>]*)?>|<(?:\d{2,}:)?\d{2}:\d{2}\.\d{3}>)`)
+	// entityPattern matches a character reference at the start of the text
+	entityPattern = regexp.MustCompile(`^&(?:[A-Za-z][A-Za-z0-9]*|#[0-9]+|#[xX][0-9A-Fa-f]+);`)
+	// assTagPattern matches SRT style overrides such as {\i1} or {\an8}
+	assTagPattern = regexp.MustCompile(`\{\\[^{}]*\}`)
+	// assStylePattern matches the overrides with a WebVTT equivalent
+	assStylePattern = regexp.MustCompile(`\{\\([ibu])([01])\}`)
+)
+
+// LookupFormat resolves a format name such as "vtt" or "WebVTT", or a file
+// name with a known extension, to one of the supported format constants
+func LookupFormat(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if format, ok := formatNames[name]; ok {
+		return format, true
+	}
+	if ext := filepath.Ext(name); ext != "" {
+		format, ok := formatNames[strings.TrimPrefix(ext, ".")]
+		return format, ok
+	}
+	return "", false
+}
+
+// WriteCaptions serializes captions in the given format
+func WriteCaptions(w io.Writer, captions []Caption, format string) error {
+	bw := bufio.NewWriter(w)
+
+	var err error
+	switch format {
+	case FormatWebVTT:
+		err = writeWebVTT(bw, captions)
+	case FormatSRT:
+		err = writeSRT(bw, captions)
+	case FormatTTML:
+		err = writeTTML(bw, captions)
+	case FormatSCC:
+		err = writeSCC(bw, captions)
+	default:
+		return ErrUnsupportedFormat
+	}
+
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeSRT writes captions as SubRip Text, renumbering cues from 1. Tags SRT
+// has no equivalent for are dropped and character references decoded.
+func writeSRT(w io.Writer, captions []Caption) error {
+	for i, caption := range captions {
+		_, err := fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n", i+1,
+			formatTimestamp(caption.StartTime, ","), formatTimestamp(caption.EndTime, ","),
+			cueText(srtText(caption.Text)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeWebVTT writes captions as a WebVTT file, using cue numbers as
+// identifiers. SRT styling becomes WebVTT tags where it can and is dropped
+// otherwise, and &, < and > in the text are escaped.
+func writeWebVTT(w io.Writer, captions []Caption) error {
+	if _, err := io.WriteString(w, "WEBVTT\n\n"); err != nil {
+		return err
+	}
+	for i, caption := range captions {
+		_, err := fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n", i+1,
+			formatTimestamp(caption.StartTime, "."), formatTimestamp(caption.EndTime, "."),
+			cueText(webvttText(caption.Text)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTTML writes captions as a minimal TTML document. Inline tags are
+// dropped since SRT/WebVTT markup has no direct TTML equivalent.
+func writeTTML(w io.Writer, captions []Caption) error {
+	header := `<?xml version="1.0" encoding="UTF-8"?>
+<tt xmlns="http://www.w3.org/ns/ttml">
+  <body>
+    <div>
+`
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+
+	for _, caption := range captions {
+		lines := strings.Split(cueText(plainCueText(caption.Text)), "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(line)
+		}
+		_, err := fmt.Fprintf(w, "      <p begin=\"%s\" end=\"%s\">%s</p>\n",
+			formatTimestamp(caption.StartTime, "."), formatTimestamp(caption.EndTime, "."),
+			strings.Join(lines, "<br/>"))
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "    </div>\n  </body>\n</tt>\n")
+	return err
+}
+
+// formatTimestamp formats seconds as HH:MM:SS<sep>mmm. The value is rounded
+// to whole milliseconds before it is split so 59.9996 becomes 00:01:00.000.
+func formatTimestamp(seconds float64, separator string) string {
+	totalMillis := int64(math.Round(math.Max(seconds, 0) * 1000))
+	hours := totalMillis / 3600000
+	minutes := (totalMillis % 3600000) / 60000
+	secs := (totalMillis % 60000) / 1000
+	millis := totalMillis % 1000
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", hours, minutes, secs, separator, millis)
+}
+
+// srtText converts cue text to SRT: SRT tags and style overrides are kept,
+// other tags are removed and character references are decoded, since SRT
+// has no escaping
+func srtText(text string) string {
+	text = markupTagPattern.ReplaceAllStringFunc(text, func(tag string) string {
+		if srtTagPattern.MatchString(tag) {
+			return tag
+		}
+		return ""
+	})
+	return html.UnescapeString(text)
+}
+
+// webvttText converts cue text to WebVTT. WebVTT tags and character
+// references are kept, SRT italic, bold and underline overrides become tags,
+// other tags are removed, and any other &, < or > is escaped.
+func webvttText(text string) string {
+	text = assStylePattern.ReplaceAllStringFunc(text, func(override string) string {
+		match := assStylePattern.FindStringSubmatch(override)
+		if match[2] == "0" {
+			return "</" + match[1] + ">"
+		}
+		return "<" + match[1] + ">"
+	})
+	text = assTagPattern.ReplaceAllString(text, "")
+
+	var b strings.Builder
+	for len(text) > 0 {
+		switch text[0] {
+		case '<':
+			if tag := webvttTagPattern.FindString(text); tag != "" {
+				b.WriteString(tag)
+				text = text[len(tag):]
+				continue
+			}
+			if loc := markupTagPattern.FindStringIndex(text); loc != nil && loc[0] == 0 {
+				text = text[loc[1]:]
+				continue
+			}
+			b.WriteString("&lt;")
+		case '>':
+			b.WriteString("&gt;")
+		case '&':
+			if entity := entityPattern.FindString(text); entity != "" {
+				b.WriteString(entity)
+				text = text[len(entity):]
+				continue
+			}
+			b.WriteString("&amp;")
+		default:
+			b.WriteByte(text[0])
+		}
+		text = text[1:]
+	}
+	return b.String()
+}
+
+// plainCueText returns cue text without any markup, for formats that do not
+// carry SRT or WebVTT styling
+func plainCueText(text string) string {
+	return html.UnescapeString(assTagPattern.ReplaceAllString(stripTags(text), ""))
+}
+
+// cueText removes blank lines, which would otherwise terminate the cue early
+func cueText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"bytes"
+	"html"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWriteCaptionsRoundTrip(t *testing.T) {
+	captions := []Caption{
+		{Index: 1, StartTime: 1.0, EndTime: 4.0, Text: "This is the first caption."},
+		{Index: 2, StartTime: 5.25, EndTime: 9.5, Text: "This is the second caption.\nIt has multiple lines."},
+		{Index: 3, StartTime: 3661.125, EndTime: 3665.0, Text: "Fish & chips <3"},
+	}
+
+	tests := []struct {
+		format    string
+		parse     func(*bytes.Buffer) ([]Caption, error)
+		tolerance float64
+	}{
+		{FormatSRT, func(b *bytes.Buffer) ([]Caption, error) { return parseSRT(b) }, 1e-9},
+		{FormatWebVTT, func(b *bytes.Buffer) ([]Caption, error) { return parseWebVTT(b) }, 1e-9},
+		{FormatTTML, func(b *bytes.Buffer) ([]Caption, error) { return parseTTML(b) }, 1e-9},
+		// SCC timing is quantized to 29.97 fps frames
+		{FormatSCC, func(b *bytes.Buffer) ([]Caption, error) { return parseSCC(b) }, sccFrameDuration / 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteCaptions(&buf, captions, tt.format); err != nil {
+				t.Fatalf("WriteCaptions returned error: %v", err)
+			}
+			output := buf.String()
+
+			parsed, err := tt.parse(&buf)
+			if err != nil {
+				t.Fatalf("Failed to parse written %s: %v\n%s", tt.format, err, output)
+			}
+			if len(parsed) != len(captions) {
+				t.Fatalf("Expected %d captions, got %d\n%s", len(captions), len(parsed), output)
+			}
+
+			for i := range captions {
+				if math.Abs(parsed[i].StartTime-captions[i].StartTime) > tt.tolerance ||
+					math.Abs(parsed[i].EndTime-captions[i].EndTime) > tt.tolerance {
+					t.Errorf("Caption %d timing changed: got %f-->%f, want %f-->%f", i+1,
+						parsed[i].StartTime, parsed[i].EndTime, captions[i].StartTime, captions[i].EndTime)
+				}
+				text := parsed[i].Text
+				if tt.format == FormatWebVTT {
+					// WebVTT cue text keeps its character references
+					text = html.UnescapeString(text)
+				}
+				if text != captions[i].Text {
+					t.Errorf("Caption %d text changed: got %q, want %q", i+1, parsed[i].Text, captions[i].Text)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteCaptionsMarkup(t *testing.T) {
+	tests := []struct {
+		format string
+		text   string
+		want   string
+	}{
+		// WebVTT-only tags and timestamps mean nothing to SRT players
+		{FormatSRT, "<v Alice>Look at <c.yellow>that</c>!</v>", "Look at that!"},
+		{FormatSRT, "Wait for <00:00:01.500>it <lang fr>voilà</lang>", "Wait for it voilà"},
+		{FormatSRT, "<i>Kept</i> <font color=\"red\">red</font> {\\an8}top", "<i>Kept</i> <font color=\"red\">red</font> {\\an8}top"},
+		{FormatSRT, "Fish &amp; chips &lt;3", "Fish & chips <3"},
+		{FormatSRT, "1 < 2 > 0", "1 < 2 > 0"},
+		// SRT and TTML text is escaped for WebVTT and SRT styling converted
+		{FormatWebVTT, "Fish & chips <3", "Fish &amp; chips &lt;3"},
+		{FormatWebVTT, "1 < 2 > 0", "1 &lt; 2 &gt; 0"},
+		{FormatWebVTT, "{\\i1}Italic{\\i0} {\\an8}<font color=\"red\">red</font>", "<i>Italic</i> red"},
+		{FormatWebVTT, "<v Alice><c.yellow>Hi</c> &amp; <00:00:01.500>bye</v>", "<v Alice><c.yellow>Hi</c> &amp; <00:00:01.500>bye</v>"},
+		{FormatTTML, "<i>Fish</i> &amp; {\\i1}chips{\\i0}", "<p begin=\"00:00:01.000\" end=\"00:00:02.000\">Fish &amp; chips</p>"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := WriteCaptions(&buf, []Caption{{StartTime: 1, EndTime: 2, Text: tt.text}}, tt.format); err != nil {
+			t.Fatalf("WriteCaptions returned error: %v", err)
+		}
+		if !strings.Contains(buf.String(), tt.want+"\n") {
+			t.Errorf("Writing %q as %s: want %q in\n%s", tt.text, tt.format, tt.want, buf.String())
+		}
+	}
+}
+
+func TestWriteCaptionsSRTWebVTTRoundTrip(t *testing.T) {
+	text := "<i>Fish</i> & chips <3\nA < B, {\\b1}bold{\\b0}"
+
+	var vtt bytes.Buffer
+	if err := WriteCaptions(&vtt, []Caption{{StartTime: 1, EndTime: 2, Text: text}}, FormatWebVTT); err != nil {
+		t.Fatalf("WriteCaptions returned error: %v", err)
+	}
+	captions, err := parseWebVTT(&vtt)
+	if err != nil || len(captions) != 1 {
+		t.Fatalf("parseWebVTT returned %+v, %v", captions, err)
+	}
+	if want := "<i>Fish</i> &amp; chips &lt;3\nA &lt; B, <b>bold</b>"; captions[0].Text != want {
+		t.Errorf("WebVTT text = %q, want %q", captions[0].Text, want)
+	}
+
+	var srt bytes.Buffer
+	if err := WriteCaptions(&srt, captions, FormatSRT); err != nil {
+		t.Fatalf("WriteCaptions returned error: %v", err)
+	}
+	captions, err = parseSRT(&srt)
+	if err != nil || len(captions) != 1 {
+		t.Fatalf("parseSRT returned %+v, %v", captions, err)
+	}
+	if want := "<i>Fish</i> & chips <3\nA < B, <b>bold</b>"; captions[0].Text != want {
+		t.Errorf("SRT text = %q, want %q", captions[0].Text, want)
+	}
+}
+
+func TestFormatTimestampRounding(t *testing.T) {
+	tests := []struct {
+		seconds   float64
+		separator string
+		want      string
+	}{
+		{0, ",", "00:00:00,000"},
+		{1.0005, ".", "00:00:01.001"},
+		{59.9996, ",", "00:01:00,000"},
+		{3599.9999, ".", "01:00:00.000"},
+		{5.25, ",", "00:00:05,250"},
+		{-1, ".", "00:00:00.000"},
+	}
+
+	for _, tt := range tests {
+		if got := formatTimestamp(tt.seconds, tt.separator); got != tt.want {
+			t.Errorf("formatTimestamp(%v) = %s, want %s", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+func TestWriteSCCCharacters(t *testing.T) {
+	captions := []Caption{{StartTime: 1, EndTime: 3, Text: "Café ♪ *À*"}}
+
+	var buf bytes.Buffer
+	if err := WriteCaptions(&buf, captions, FormatSCC); err != nil {
+		t.Fatalf("WriteCaptions returned error: %v", err)
+	}
+
+	parsed, err := parseSCC(&buf)
+	if err != nil {
+		t.Fatalf("parseSCC returned error: %v", err)
+	}
+	if len(parsed) != 1 || parsed[0].Text != "Café ♪ *À*" {
+		t.Errorf("Unexpected captions: %+v", parsed)
+	}
+}
+
+func TestFormatSCCTimecode(t *testing.T) {
+	for _, frame := range []int{0, 1, 1799, 1800, 17982, 107892, 123456} {
+		timecode := formatSCCTimecode(frame)
+		got, err := parseSCCTimecode(timecode)
+		if err != nil {
+			t.Fatalf("parseSCCTimecode(%q) returned error: %v", timecode, err)
+		}
+		if got != frame {
+			t.Errorf("Frame %d round-tripped through %s as %d", frame, timecode, got)
+		}
+	}
+
+	if got := formatSCCTimecode(1800); got != "00:01:00;02" {
+		t.Errorf("formatSCCTimecode(1800) = %s, want 00:01:00;02", got)
+	}
+}
+
+func TestLookupFormat(t *testing.T) {
+	tests := map[string]string{
+		"vtt":         FormatWebVTT,
+		"WebVTT":      FormatWebVTT,
+		"srt":         FormatSRT,
+		"dfxp":        FormatTTML,
+		"episode.scc": FormatSCC,
+		"out.TTML":    FormatTTML,
+	}
+	for name, want := range tests {
+		if got, ok := LookupFormat(name); !ok || got != want {
+			t.Errorf("LookupFormat(%q) = %q, %v; want %q", name, got, ok, want)
+		}
+	}
+
+	if _, ok := LookupFormat("docx"); ok {
+		t.Error("Expected LookupFormat to reject unknown format")
+	}
+	if err := WriteCaptions(&bytes.Buffer{}, nil, "docx"); err != ErrUnsupportedFormat {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+	}
+}
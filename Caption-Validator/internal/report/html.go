@@ -0,0 +1,183 @@
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"time"
+
+	"caption-validator/internal/validator"
+)
+
+//go:embed html.tmpl
+var htmlSource string
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"timestamp": formatTimestamp,
+}).Parse(htmlSource))
+
+// htmlPage is the data of the HTML template
+type htmlPage struct {
+	Generated string
+	Passed    int
+	Failed    int
+	Errors    int
+	Files     []htmlFile
+}
+
+// htmlFile is one caption file's section of the page
+type htmlFile struct {
+	*Report
+	ID       int
+	Segments []htmlSpan
+	Markers  []htmlSpan
+	Findings []htmlFinding
+	Cues     []htmlCue
+}
+
+// htmlSpan is a positioned piece of the timeline, in percent of its width
+type htmlSpan struct {
+	Left  string
+	Width string
+	Class string
+	Title string
+}
+
+// htmlFinding is a finding with a link to its cue in the cue table
+type htmlFinding struct {
+	Finding
+	Cue int // row number in the cue table, 0 if the finding is not about a cue
+}
+
+// htmlCue is a row of the cue table
+type htmlCue struct {
+	Row       int
+	Index     int
+	Line      int
+	StartTime float64
+	EndTime   float64
+	Duration  float64
+	CPS       float64
+	WPM       float64
+	Text      string
+	Class     string // severity of the worst finding about the cue
+	Findings  int
+}
+
+// WriteHTML writes the reports as a single standalone HTML page for QC
+// reviewers. Each file has a timeline of [t_start, t_end] showing where
+// captions are on screen, where they are missing and where the findings are,
+// followed by the findings and a table of every cue with its timing and
+// reading speed. The page has no external dependencies.
+func WriteHTML(w io.Writer, reports []*Report) error {
+	page := htmlPage{Generated: time.Now().Format(time.RFC1123)}
+	for i, r := range reports {
+		switch {
+		case r.Error != nil:
+			page.Errors++
+		case r.Summary.Passed:
+			page.Passed++
+		default:
+			page.Failed++
+		}
+		page.Files = append(page.Files, newHTMLFile(i+1, r))
+	}
+	return htmlTemplate.Execute(w, page)
+}
+
+// newHTMLFile lays out the timeline and cue table of one report
+func newHTMLFile(id int, r *Report) htmlFile {
+	file := htmlFile{Report: r, ID: id}
+	start, end := r.Params.StartTime, r.Params.EndTime
+	// Files that could not be parsed have nothing to draw
+	if r.Format == "" || end <= start {
+		return file
+	}
+
+	span := func(from float64, to float64, class string, title string) htmlSpan {
+		from = math.Max(from, start)
+		to = math.Min(to, end)
+		return htmlSpan{
+			Left:  fmt.Sprintf("%.4f", (from-start)/(end-start)*100),
+			Width: fmt.Sprintf("%.4f", math.Max(to-from, 0)/(end-start)*100),
+			Class: class,
+			Title: title,
+		}
+	}
+
+	// Covered stretches are the complement of the gaps the coverage rule uses
+	gaps, _ := validator.FindGaps(r.Captions, start, end)
+	cursor := start
+	for _, gap := range gaps {
+		if gap.Start > cursor {
+			file.Segments = append(file.Segments, span(cursor, gap.Start, "covered",
+				fmt.Sprintf("Captioned %s - %s", formatTimestamp(cursor), formatTimestamp(gap.Start))))
+		}
+		file.Segments = append(file.Segments, span(gap.Start, gap.End, "gap",
+			fmt.Sprintf("No captions %s - %s (%.3fs)", formatTimestamp(gap.Start), formatTimestamp(gap.End), gap.Duration)))
+		cursor = gap.End
+	}
+	if cursor < end {
+		file.Segments = append(file.Segments, span(cursor, end, "covered",
+			fmt.Sprintf("Captioned %s - %s", formatTimestamp(cursor), formatTimestamp(end))))
+	}
+
+	// Rows are matched to findings by cue index and timing, since SRT
+	// numbering may repeat
+	rows := make(map[[3]float64]int)
+	for i, caption := range r.Captions {
+		row := htmlCue{
+			Row:       i + 1,
+			Index:     caption.Index,
+			Line:      caption.Line,
+			StartTime: caption.StartTime,
+			EndTime:   caption.EndTime,
+			Duration:  math.Round((caption.EndTime-caption.StartTime)*1000) / 1000,
+			Text:      caption.PlainText(),
+		}
+		if speed, ok := validator.MeasureReadingSpeed(caption); ok {
+			row.CPS, row.WPM = speed.CPS, speed.WPM
+		}
+		key := [3]float64{float64(caption.Index), caption.StartTime, caption.EndTime}
+		if _, seen := rows[key]; !seen {
+			rows[key] = i
+		}
+		file.Cues = append(file.Cues, row)
+	}
+
+	for _, finding := range r.Findings {
+		entry := htmlFinding{Finding: finding}
+		location := finding.Location
+		if location != nil && location.CueIndex > 0 {
+			if i, ok := rows[[3]float64{float64(location.CueIndex), location.StartTime, location.EndTime}]; ok {
+				cue := &file.Cues[i]
+				entry.Cue = cue.Row
+				cue.Findings++
+				if cue.Class == "" || cue.Class == string(validator.SeverityInfo) ||
+					(cue.Class == string(validator.SeverityWarning) && finding.Severity == validator.SeverityError) {
+					cue.Class = string(finding.Severity)
+				}
+			}
+		}
+		// Findings about the whole range, such as coverage, are not marked
+		if location != nil && (location.StartTime > start || location.EndTime < end) {
+			file.Markers = append(file.Markers, span(location.StartTime, location.EndTime, string(finding.Severity),
+				fmt.Sprintf("%s at %s: %s", finding.Rule, formatTimestamp(location.StartTime), finding.Message)))
+		}
+		file.Findings = append(file.Findings, entry)
+	}
+
+	return file
+}
+
+// formatTimestamp formats seconds as HH:MM:SS.mmm
+func formatTimestamp(seconds float64) string {
+	millis := int64(math.Round(seconds * 1000))
+	sign := ""
+	if millis < 0 {
+		sign, millis = "-", -millis
+	}
+	return fmt.Sprintf("%s%02d:%02d:%02d.%03d", sign, millis/3600000, millis/60000%60, millis/1000%60, millis%1000)
+}
@@ -0,0 +1,80 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"caption-validator/internal/parser"
+	"caption-validator/internal/validator"
+)
+
+func TestWriteHTML(t *testing.T) {
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 10, EndTime: 20, Text: "Hello <i>there</i>", Line: 4},
+		{Index: 2, StartTime: 20, EndTime: 21, Text: "<script>alert(1)</script> far too fast to read", Line: 8},
+	}
+	profile := validator.Profile{Name: "house", Rules: []validator.RuleConfig{
+		{Rule: "coverage", Params: validator.Params{"min_coverage": 95.0}},
+		{Rule: "reading_speed", Severity: "warning", Params: validator.Params{"max_cps": 17.0}},
+	}}
+	engine, err := validator.NewEngine(profile)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	checked := New("episode1.vtt", Params{StartTime: 0, EndTime: 40, Profile: profile})
+	checked.Format = parser.FormatWebVTT
+	checked.Captions = captions
+	checked.AddOutcomes(engine.Run(validator.Context{Captions: captions, StartTime: 0, EndTime: 40}))
+	unsupported := New("notes.txt", Params{EndTime: 40, Profile: profile})
+	unsupported.Fail("unsupported_format", "Unsupported caption file format")
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, []*Report{checked, unsupported}); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	page := buf.String()
+
+	for _, want := range []string{
+		"2 files: 0 passed, 1 failed, 1 could not be validated",
+		// Timeline: a gap from 0-10s, captions from 10-21s, a gap from 21-40s
+		`<div class="gap" style="left: 0.0000%; width: 25.0000%"`,
+		`<div class="covered" style="left: 25.0000%; width: 27.5000%"`,
+		`<div class="gap" style="left: 52.5000%; width: 47.5000%"`,
+		// The reading speed warning is marked at cue 2 and links to its row
+		`<div class="warning" style="left: 50.0000%; width: 2.5000%"`,
+		`<a href="#file-1-cue-2">2</a>`,
+		`<tr id="file-1-cue-2" class="warning">`,
+		"<td>00:00:20.000</td>",
+		"Unsupported caption file format",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("HTML report is missing %q", want)
+		}
+	}
+
+	// Caption text is escaped, and markup is not shown
+	if strings.Contains(page, "<script>alert") || strings.Contains(page, "<i>there") {
+		t.Error("Caption text must be escaped and stripped of markup")
+	}
+	// The coverage finding spans the whole range, so it has no marker
+	if strings.Count(page, `<div class="error"`) != 0 {
+		t.Error("Findings about the whole range should not be marked on the timeline")
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	tests := map[float64]string{
+		0:        "00:00:00.000",
+		61.5:     "00:01:01.500",
+		3725.004: "01:02:05.004",
+		59.9996:  "00:01:00.000",
+		-1.5:     "-00:00:01.500",
+	}
+	for seconds, want := range tests {
+		if got := formatTimestamp(seconds); got != want {
+			t.Errorf("formatTimestamp(%v) = %q, want %q", seconds, got, want)
+		}
+	}
+}
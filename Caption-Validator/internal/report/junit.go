@@ -0,0 +1,193 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+
+	"caption-validator/internal/validator"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     junitTime        `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       junitTime       `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+// junitTime is a duration in seconds, written in decimal notation as JUnit
+// consumers expect
+type junitTime float64
+
+// MarshalXMLAttr implements xml.MarshalerAttr
+func (t junitTime) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: strconv.FormatFloat(float64(t), 'f', 6, 64)}, nil
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      junitTime     `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",cdata"`
+}
+
+type junitOutput struct {
+	Body string `xml:",cdata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// WriteJUnit writes the reports as JUnit XML for CI dashboards: each caption
+// file is a testsuite and each rule of the profile a testcase. A rule with
+// error findings fails and its failure element holds the findings as JSON;
+// warning and info findings are attached as system-out and do not fail the
+// testcase. A file that could not be validated has a single testcase, named
+// after the error type, that errors.
+func WriteJUnit(w io.Writer, reports []*Report) error {
+	suites := junitTestSuites{Name: toolName, Suites: []junitTestSuite{}}
+
+	for _, r := range reports {
+		suite := junitTestSuite{
+			Name: r.File,
+			Time: seconds(r.Timings.TotalMS),
+			Properties: []junitProperty{
+				{Name: "schema_version", Value: r.SchemaVersion},
+				{Name: "format", Value: r.Format},
+				{Name: "profile", Value: r.Params.Profile.Name},
+				{Name: "start_time", Value: fmt.Sprint(r.Params.StartTime)},
+				{Name: "end_time", Value: fmt.Sprint(r.Params.EndTime)},
+			},
+		}
+
+		for _, rule := range r.Rules {
+			testCase := junitTestCase{Name: rule.Rule, ClassName: r.File, Time: seconds(rule.DurationMS)}
+			findings := r.findingsOf(rule.Rule)
+
+			switch {
+			case rule.Status == StatusSkipped:
+				testCase.Skipped = &junitSkipped{Message: rule.Message}
+				suite.Skipped++
+			case rule.Status == StatusError:
+				errorType := "validation_error"
+				if rule.unavailable {
+					errorType = "service_error"
+				}
+				testCase.Error = &junitProblem{Message: rule.Message, Type: errorType}
+				suite.Errors++
+			case rule.Status == StatusFailed && rule.Severity == validator.SeverityError:
+				body, err := junitFindings(findings)
+				if err != nil {
+					return err
+				}
+				testCase.Failure = &junitProblem{Message: junitMessage(findings), Type: rule.Type, Body: body}
+				suite.Failures++
+			case rule.Status == StatusFailed:
+				body, err := junitFindings(findings)
+				if err != nil {
+					return err
+				}
+				testCase.SystemOut = &junitOutput{Body: body}
+			}
+			suite.Cases = append(suite.Cases, testCase)
+		}
+
+		// Rule errors already have a testcase; other problems with the file get one
+		if r.Error != nil && suite.Errors == 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      r.Error.Type,
+				ClassName: r.File,
+				Error:     &junitProblem{Message: r.Error.Message, Type: r.Error.Type},
+			})
+			suite.Errors++
+		}
+
+		suite.Tests = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+		suites.Time += suite.Time
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// findingsOf returns the findings reported by one rule
+func (r *Report) findingsOf(rule string) []Finding {
+	var findings []Finding
+	for _, finding := range r.Findings {
+		if finding.Rule == rule {
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// junitMessage summarises a rule's findings in one line
+func junitMessage(findings []Finding) string {
+	switch len(findings) {
+	case 0:
+		return ""
+	case 1:
+		return findings[0].Message
+	default:
+		return fmt.Sprintf("%s (and %d more)", findings[0].Message, len(findings)-1)
+	}
+}
+
+// junitFindings renders findings as the indented JSON of the report
+func junitFindings(findings []Finding) (string, error) {
+	data, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// seconds converts milliseconds to the seconds JUnit reports time in
+func seconds(ms float64) junitTime {
+	return junitTime(ms / 1000)
+}
@@ -0,0 +1,97 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	"caption-validator/internal/parser"
+	"caption-validator/internal/validator"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 0, EndTime: 1, Text: "Far too much text to read in a single second"},
+		{Index: 2, StartTime: 1, EndTime: 30, Text: "A line that is rather long"},
+	}
+	profile := validator.Profile{Name: "house", Rules: []validator.RuleConfig{
+		{Rule: "coverage", Params: validator.Params{"min_coverage": 95.0}},
+		{Rule: "reading_speed", Params: validator.Params{"max_cps": 20.0}},
+		{Rule: "line_length", Severity: "warning", Params: validator.Params{"max_chars_per_line": 20}},
+		{Rule: "cue_ordering"},
+		{Rule: "language"},
+	}}
+	engine, err := validator.NewEngine(profile)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	checked := New("episode1.vtt", Params{EndTime: 60, Profile: profile})
+	checked.AddOutcomes(engine.Run(validator.Context{Captions: captions, StartTime: 0, EndTime: 60}))
+	unsupported := New("notes.txt", Params{Profile: profile})
+	unsupported.Fail("unsupported_format", "Unsupported caption file format")
+	broken := New("episode2.vtt", Params{Profile: profile})
+	broken.AddOutcomes([]validator.Outcome{{Rule: "coverage", Err: errors.New("bad range")}})
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, []*Report{checked, unsupported, broken}); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("JUnit output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if suites.Tests != 7 || suites.Failures != 2 || suites.Errors != 2 || suites.Skipped != 1 {
+		t.Errorf("Unexpected totals: %d tests, %d failures, %d errors, %d skipped",
+			suites.Tests, suites.Failures, suites.Errors, suites.Skipped)
+	}
+	if len(suites.Suites) != 3 {
+		t.Fatalf("Expected one testsuite per file, got %d", len(suites.Suites))
+	}
+
+	suite := suites.Suites[0]
+	if suite.Name != "episode1.vtt" || len(suite.Cases) != 5 {
+		t.Fatalf("Expected one testcase per rule, got %+v", suite)
+	}
+	coverage, speed, lines, ordering, language := suite.Cases[0], suite.Cases[1], suite.Cases[2], suite.Cases[3], suite.Cases[4]
+	if coverage.Failure == nil || coverage.Failure.Type != "caption_coverage" {
+		t.Errorf("Expected coverage to fail, got %+v", coverage)
+	}
+
+	// The failure carries the structured findings
+	if speed.Failure == nil {
+		t.Fatalf("Expected reading speed to fail, got %+v", speed)
+	}
+	var findings []Finding
+	if err := json.Unmarshal([]byte(speed.Failure.Body), &findings); err != nil {
+		t.Fatalf("Failure body is not the findings as JSON: %v\n%s", err, speed.Failure.Body)
+	}
+	if len(findings) != 1 || findings[0].Location == nil || findings[0].Location.CueIndex != 1 {
+		t.Errorf("Unexpected findings in failure: %+v", findings)
+	}
+	if speed.Failure.Message != findings[0].Message {
+		t.Errorf("Expected the finding's message, got %q", speed.Failure.Message)
+	}
+
+	// Warnings are reported without failing the testcase
+	if lines.Failure != nil || lines.SystemOut == nil || !strings.Contains(lines.SystemOut.Body, "line_too_long") {
+		t.Errorf("Expected line length warning as system-out, got %+v", lines)
+	}
+	if ordering.Failure != nil || ordering.Error != nil || ordering.Skipped != nil {
+		t.Errorf("Expected cue ordering to pass, got %+v", ordering)
+	}
+	if language.Skipped == nil {
+		t.Errorf("Expected language to be skipped, got %+v", language)
+	}
+
+	if cases := suites.Suites[1].Cases; len(cases) != 1 || cases[0].Error == nil || cases[0].Name != "unsupported_format" {
+		t.Errorf("Expected an unsupported_format error testcase, got %+v", cases)
+	}
+	if cases := suites.Suites[2].Cases; len(cases) != 1 || cases[0].Error == nil || cases[0].Name != "coverage" {
+		t.Errorf("Expected the rule error as a testcase error, got %+v", cases)
+	}
+}
@@ -0,0 +1,261 @@
+// Package report builds the machine-readable result of validating a caption
+// file. The JSON form of Report is described by schema.json and versioned by
+// SchemaVersion: fields may be added within a major version, but never
+// renamed or removed.
+package report
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"time"
+
+	"caption-validator/internal/parser"
+	"caption-validator/internal/validator"
+)
+
+// SchemaVersion is the version of the report format
+const SchemaVersion = "1.5"
+
+// Schema is the JSON Schema describing a Report
+//
+//go:embed schema.json
+var Schema []byte
+
+// Rule statuses
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped" // the rule could not run, e.g. no language API
+	StatusError   = "error"   // the rule rejected its input
+)
+
+// Report is the result of validating one caption file
+type Report struct {
+	SchemaVersion string `json:"schema_version"`
+	File          string `json:"file"`
+	Format        string `json:"format,omitempty"`
+	// FormatConfidence is how sure format detection was, from 0 to 1. It is
+	// omitted when the format was given with -input-format. Added in 1.3.
+	FormatConfidence float64      `json:"format_confidence,omitempty"`
+	Params           Params       `json:"params"`
+	Rules            []RuleResult `json:"rules"`
+	Findings         []Finding    `json:"findings"`
+	Summary          Summary      `json:"summary"`
+	Timings          Timings      `json:"timings"`
+	Error            *Error       `json:"error,omitempty"` // set when the file could not be validated
+
+	// Captions are the parsed cues, kept for renderers that show them. They
+	// are not part of the JSON report.
+	Captions []parser.Caption `json:"-"`
+}
+
+// Params are the settings the file was validated with
+type Params struct {
+	StartTime        float64           `json:"start_time"`
+	EndTime          float64           `json:"end_time"`
+	ExpectedLanguage string            `json:"expected_language,omitempty"`
+	Profile          validator.Profile `json:"profile"`
+}
+
+// RuleResult is the outcome of one rule of the profile. Data holds the
+// rule's scalar measurements and limits (e.g. actual_coverage) and the
+// coverage rule's gaps, which a passing file has no finding for (added in
+// 1.5); per-cue details are reported as findings instead.
+type RuleResult struct {
+	Rule       string                 `json:"rule"`
+	Type       string                 `json:"type,omitempty"`
+	Severity   validator.Severity     `json:"severity"`
+	Status     string                 `json:"status"`
+	Message    string                 `json:"message,omitempty"` // why the rule was skipped or errored
+	Findings   int                    `json:"findings"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	DurationMS float64                `json:"duration_ms"`
+
+	unavailable   bool // skipped or errored because a service it depends on failed
+	notApplicable bool // skipped because it does not apply to the file's format
+}
+
+// Finding is one problem in the file, tagged with the rule that found it
+type Finding struct {
+	Rule     string             `json:"rule"`
+	Type     string             `json:"type"`
+	Severity validator.Severity `json:"severity"`
+	validator.Finding
+}
+
+// Summary counts findings by severity. A file passes when it could be
+// validated and has no error findings.
+type Summary struct {
+	Passed       bool `json:"passed"`
+	Errors       int  `json:"errors"`
+	Warnings     int  `json:"warnings"`
+	Info         int  `json:"info"`
+	RulesPassed  int  `json:"rules_passed"`
+	RulesFailed  int  `json:"rules_failed"`
+	RulesSkipped int  `json:"rules_skipped"` // not counting rules that do not apply to the format
+	// ServiceErrors counts the rules whose external service (such as the
+	// language API) failed, whether skipped or, in strict mode, errored.
+	// Added in 1.2.
+	ServiceErrors int `json:"service_errors"`
+}
+
+// Timings records where the time went, in milliseconds
+type Timings struct {
+	ParseMS    float64 `json:"parse_ms"`
+	ValidateMS float64 `json:"validate_ms"`
+	TotalMS    float64 `json:"total_ms"`
+}
+
+// Error describes why a file could not be validated
+type Error struct {
+	Type    string `json:"type"` // e.g. file_not_found, unsupported_format, parse_error
+	Message string `json:"message"`
+}
+
+// New returns an empty, passing report for a file
+func New(file string, params Params) *Report {
+	r := &Report{
+		SchemaVersion: SchemaVersion,
+		File:          file,
+		Params:        params,
+		Rules:         []RuleResult{},
+		Findings:      []Finding{},
+	}
+	r.summarise()
+	return r
+}
+
+// Fail records that the file could not be validated
+func (r *Report) Fail(errorType string, message string) {
+	r.Error = &Error{Type: errorType, Message: message}
+	r.summarise()
+}
+
+// AddOutcomes records the engine's outcomes in profile order. The first rule
+// that errors (other than by skipping) fails the report as a validation_error,
+// or a service_error when a service it depends on failed in strict mode.
+func (r *Report) AddOutcomes(outcomes []validator.Outcome) {
+	for _, outcome := range outcomes {
+		rule := RuleResult{
+			Rule:       outcome.Rule,
+			Type:       outcome.Result.Type,
+			Severity:   outcome.Severity,
+			Status:     StatusPassed,
+			DurationMS: Millis(outcome.Duration),
+		}
+
+		switch {
+		case errors.Is(outcome.Err, validator.ErrSkipped):
+			rule.Status = StatusSkipped
+			rule.Message = outcome.Err.Error()
+			rule.unavailable = errors.Is(outcome.Err, validator.ErrUnavailable)
+			rule.notApplicable = errors.Is(outcome.Err, validator.ErrNotApplicable)
+		case outcome.Err != nil:
+			rule.Status = StatusError
+			rule.Message = outcome.Err.Error()
+			rule.unavailable = errors.Is(outcome.Err, validator.ErrUnavailable)
+			if r.Error == nil {
+				errorType := "validation_error"
+				if rule.unavailable {
+					errorType = "service_error"
+				}
+				r.Error = &Error{Type: errorType, Message: outcome.Err.Error()}
+			}
+		default:
+			rule.Data = measurements(outcome.Result.Data)
+			if !outcome.Result.Valid {
+				rule.Status = StatusFailed
+				rule.Findings = len(outcome.Result.Findings)
+				for _, finding := range outcome.Result.Findings {
+					r.Findings = append(r.Findings, Finding{
+						Rule:     outcome.Rule,
+						Type:     outcome.Result.Type,
+						Severity: outcome.Severity,
+						Finding:  finding,
+					})
+				}
+			}
+		}
+
+		r.Rules = append(r.Rules, rule)
+	}
+	r.summarise()
+}
+
+// summarise recomputes the summary from the rules and findings
+func (r *Report) summarise() {
+	summary := Summary{}
+	for _, rule := range r.Rules {
+		switch rule.Status {
+		case StatusPassed:
+			summary.RulesPassed++
+		case StatusFailed:
+			summary.RulesFailed++
+		case StatusSkipped:
+			// A rule that does not apply, such as line limits on a format
+			// without any, is not a check that was missed
+			if !rule.notApplicable {
+				summary.RulesSkipped++
+			}
+		}
+		if rule.unavailable {
+			summary.ServiceErrors++
+		}
+	}
+	for _, finding := range r.Findings {
+		switch finding.Severity {
+		case validator.SeverityError:
+			summary.Errors++
+		case validator.SeverityWarning:
+			summary.Warnings++
+		default:
+			summary.Info++
+		}
+	}
+	summary.Passed = r.Error == nil && summary.Errors == 0
+	r.Summary = summary
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// Millis converts a duration to milliseconds, rounded to the microsecond
+func Millis(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Microsecond)) / 1000
+}
+
+// dataLists are the lists of a result's data kept in the report, because
+// they are only reported as findings when the rule fails. The uncaptioned
+// gaps tell editors where the holes are even when coverage passes.
+var dataLists = map[string]bool{"gaps": true}
+
+// measurements keeps the scalar entries of a result's data and dataLists.
+// Other lists such as the too-fast captions are already reported one finding
+// per entry.
+func measurements(data map[string]interface{}) map[string]interface{} {
+	kept := make(map[string]interface{})
+	for key, value := range data {
+		if value == nil {
+			continue
+		}
+		switch reflect.ValueOf(value).Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+			if !dataLists[key] {
+				continue
+			}
+		}
+		kept[key] = value
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
@@ -0,0 +1,200 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"caption-validator/internal/client"
+	"caption-validator/internal/parser"
+	"caption-validator/internal/validator"
+)
+
+func TestAddOutcomes(t *testing.T) {
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 0, EndTime: 10, Text: "This caption is rather long for one line"},
+		{Index: 2, StartTime: 40, EndTime: 60, Text: "Short"},
+	}
+	profile := validator.Profile{Name: "test", Rules: []validator.RuleConfig{
+		{Rule: "coverage", Params: validator.Params{"min_coverage": 95.0}},
+		{Rule: "line_length", Severity: "warning", Params: validator.Params{"max_chars_per_line": 32}},
+		{Rule: "cue_ordering"},
+		{Rule: "language"},
+	}}
+	engine, err := validator.NewEngine(profile)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	r := New("episode.vtt", Params{StartTime: 0, EndTime: 60, Profile: profile})
+	if !r.Summary.Passed || r.SchemaVersion != SchemaVersion {
+		t.Fatalf("A new report should pass, got %+v", r)
+	}
+	r.AddOutcomes(engine.Run(validator.Context{Captions: captions, StartTime: 0, EndTime: 60}))
+
+	want := Summary{Passed: false, Errors: 1, Warnings: 1, RulesPassed: 1, RulesFailed: 2, RulesSkipped: 1}
+	if r.Summary != want {
+		t.Errorf("Summary = %+v, want %+v", r.Summary, want)
+	}
+
+	if len(r.Rules) != 4 {
+		t.Fatalf("Expected one entry per rule, got %+v", r.Rules)
+	}
+	coverage := r.Rules[0]
+	if coverage.Status != StatusFailed || coverage.Findings != 1 || coverage.Data["actual_coverage"] != 50.0 {
+		t.Errorf("Unexpected coverage rule result: %+v", coverage)
+	}
+	if gaps, ok := coverage.Data["gaps"].([]validator.Gap); !ok || len(gaps) != 1 {
+		t.Errorf("Expected the gap to be kept in rule data, got %+v", coverage.Data)
+	}
+	if data := measurements(map[string]interface{}{"ranges": []validator.LanguageRange{}, "segments": 3}); len(data) != 1 {
+		t.Errorf("Lists other than gaps should not be repeated in rule data, got %+v", data)
+	}
+	if language := r.Rules[3]; language.Status != StatusSkipped || language.Message == "" {
+		t.Errorf("Expected the language rule to be skipped, got %+v", language)
+	}
+
+	lineLength := r.Findings[1]
+	if lineLength.Rule != "line_length" || lineLength.Severity != validator.SeverityWarning ||
+		lineLength.Location == nil || lineLength.Location.CueIndex != 1 || lineLength.Location.Line != 1 {
+		t.Errorf("Unexpected line length finding: %+v", lineLength)
+	}
+
+	// A language API failure skips the rule and counts as a service error
+	r = New("episode.vtt", Params{StartTime: 0, EndTime: 60, Profile: profile})
+	r.AddOutcomes(engine.Run(validator.Context{
+		Captions:  captions,
+		StartTime: 0,
+		EndTime:   60,
+		ValidateLanguage: func(string, string) (client.LanguageValidationResult, error) {
+			return client.LanguageValidationResult{}, errors.New("connection refused")
+		},
+	}))
+	if r.Summary.RulesSkipped != 1 || r.Summary.ServiceErrors != 1 {
+		t.Errorf("Expected one service error, got %+v", r.Summary)
+	}
+
+	// In strict mode the failure errors the rule and fails the report
+	r = New("episode.vtt", Params{StartTime: 0, EndTime: 60, Profile: profile})
+	r.AddOutcomes(engine.Run(validator.Context{
+		Captions:  captions,
+		StartTime: 0,
+		EndTime:   60,
+		ValidateLanguage: func(string, string) (client.LanguageValidationResult, error) {
+			return client.LanguageValidationResult{}, errors.New("connection refused")
+		},
+		Strict: true,
+	}))
+	if r.Summary.RulesSkipped != 0 || r.Summary.ServiceErrors != 1 || r.Rules[3].Status != StatusError ||
+		r.Error == nil || r.Error.Type != "service_error" {
+		t.Errorf("Expected a service_error report, got %+v, %+v", r.Summary, r.Error)
+	}
+
+	// Line limits do not apply to WebVTT unless set, which is not counted as
+	// a skipped rule
+	engine, err = validator.NewEngine(validator.Profile{Rules: []validator.RuleConfig{{Rule: "line_length"}}})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	r = New("episode.vtt", Params{StartTime: 0, EndTime: 60})
+	r.AddOutcomes(engine.Run(validator.Context{Captions: captions, Format: parser.FormatWebVTT, StartTime: 0, EndTime: 60}))
+	if r.Rules[0].Status != StatusSkipped || r.Summary.RulesSkipped != 0 || !r.Summary.Passed {
+		t.Errorf("Expected line_length to be skipped without counting, got %+v, %+v", r.Rules[0], r.Summary)
+	}
+}
+
+func TestReportCoverageGaps(t *testing.T) {
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 1, EndTime: 12, Text: "Hello there"},
+		{Index: 2, StartTime: 12, EndTime: 25, Text: "General Kenobi"},
+	}
+	profile := validator.Profile{Name: "test", Rules: []validator.RuleConfig{
+		{Rule: "coverage", Params: validator.Params{"min_coverage": 10.0}},
+	}}
+	engine, err := validator.NewEngine(profile)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	r := New("episode.vtt", Params{StartTime: 0, EndTime: 25, Profile: profile})
+	r.AddOutcomes(engine.Run(validator.Context{Captions: captions, StartTime: 0, EndTime: 25}))
+	if !r.Summary.Passed || len(r.Findings) != 0 {
+		t.Fatalf("Expected coverage to pass without findings, got %+v", r)
+	}
+
+	// The hole before the first caption is in the JSON although the rule passed
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var decoded struct {
+		Rules []struct {
+			Data struct {
+				Gaps []validator.Gap `json:"gaps"`
+			} `json:"data"`
+		} `json:"rules"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Report is not valid JSON: %v", err)
+	}
+	want := []validator.Gap{{Start: 0, End: 1, Duration: 1}}
+	if len(decoded.Rules) != 1 || fmt.Sprint(decoded.Rules[0].Data.Gaps) != fmt.Sprint(want) {
+		t.Errorf("Expected gaps %v in the coverage rule's data, got %s", want, buf.String())
+	}
+}
+
+func TestReportFail(t *testing.T) {
+	r := New("notes.txt", Params{})
+	r.Fail("unsupported_format", "Unsupported caption file format")
+	if r.Summary.Passed || r.Error == nil || r.Error.Type != "unsupported_format" {
+		t.Errorf("Expected a failed report, got %+v", r)
+	}
+
+	// A rule that rejects its input fails the report too
+	r = New("episode.vtt", Params{})
+	r.AddOutcomes([]validator.Outcome{{Rule: "coverage", Severity: validator.SeverityError, Err: fmt.Errorf("bad range")}})
+	if r.Summary.Passed || r.Error == nil || r.Error.Type != "validation_error" || r.Rules[0].Status != StatusError {
+		t.Errorf("Expected a validation_error report, got %+v", r)
+	}
+}
+
+func TestReportMatchesSchema(t *testing.T) {
+	var schema struct {
+		ID         string                     `json:"$id"`
+		Required   []string                   `json:"required"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		t.Fatalf("schema.json is not valid JSON: %v", err)
+	}
+	if !bytes.Contains([]byte(schema.ID), []byte("/"+SchemaVersion+"/")) {
+		t.Errorf("Schema $id %q does not carry version %s", schema.ID, SchemaVersion)
+	}
+
+	r := New("episode.vtt", Params{EndTime: 60})
+	r.Timings.TotalMS = Millis(1500 * time.Microsecond)
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Report is not valid JSON: %v", err)
+	}
+
+	for _, key := range schema.Required {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("Report is missing required property %q", key)
+		}
+	}
+	for key := range decoded {
+		if _, ok := schema.Properties[key]; !ok {
+			t.Errorf("Report property %q is not described by the schema", key)
+		}
+	}
+	if total := decoded["timings"].(map[string]interface{})["total_ms"]; total != 1.5 {
+		t.Errorf("Expected total_ms 1.5, got %v", total)
+	}
+}
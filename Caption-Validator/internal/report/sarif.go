@@ -0,0 +1,209 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+
+	"caption-validator/internal/validator"
+)
+
+// SARIF 2.1.0, the static analysis format understood by code review tools
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "caption-validator"
+)
+
+// ruleDescriptions are shown by code review tools next to each finding
+var ruleDescriptions = map[string]string{
+	"coverage":           "Captions must cover enough of the validated time range",
+	"max_gap":            "No stretch of the programme may go uncaptioned for too long",
+	"reading_speed":      "Captions must not be too fast to read",
+	"line_length":        "Caption lines must fit the line length and line count limits",
+	"cue_timing":         "Cues must be on screen long enough, not too long, and separated by a minimum gap",
+	"cue_ordering":       "Cues must be in time order, must not overlap and, for SRT, must be numbered in sequence",
+	"allowed_characters": "Caption text must only use characters of the delivery character set",
+	"language":           "Caption text must be in the expected language",
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Artifacts   []sarifArtifact   `json:"artifacts"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful bool                `json:"executionSuccessful"`
+	Notifications       []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifArtifact struct {
+	Location sarifArtifactLocation `json:"location"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	RuleIndex  int                    `json:"ruleIndex"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []sarifLocation        `json:"locations"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI   string `json:"uri"`
+	Index int    `json:"index"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// WriteSARIF writes the reports as a single SARIF run. Each finding becomes a
+// result located in its caption file, at the cue's line when the finding is
+// about a cue. Files that could not be validated are reported as tool
+// execution notifications.
+func WriteSARIF(w io.Writer, reports []*Report) error {
+	run := sarifRun{
+		Tool:      sarifTool{Driver: sarifDriver{Name: toolName, Rules: []sarifRule{}}},
+		Artifacts: []sarifArtifact{},
+		Results:   []sarifResult{},
+	}
+	invocation := sarifInvocation{ExecutionSuccessful: true}
+
+	// Rules are listed once, sorted, and referenced by index
+	ruleIndex := make(map[string]int)
+	var ruleIDs []string
+	for _, r := range reports {
+		for _, rule := range r.Rules {
+			if _, ok := ruleIndex[rule.Rule]; !ok {
+				ruleIndex[rule.Rule] = 0
+				ruleIDs = append(ruleIDs, rule.Rule)
+			}
+		}
+	}
+	sort.Strings(ruleIDs)
+	for i, id := range ruleIDs {
+		ruleIndex[id] = i
+		description := ruleDescriptions[id]
+		if description == "" {
+			description = id
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: description}})
+	}
+
+	for i, r := range reports {
+		artifact := sarifArtifactLocation{URI: artifactURI(r.File), Index: i}
+		run.Artifacts = append(run.Artifacts, sarifArtifact{Location: artifact})
+
+		if r.Error != nil {
+			invocation.ExecutionSuccessful = false
+			invocation.Notifications = append(invocation.Notifications, sarifNotification{
+				Level:     "error",
+				Message:   sarifMessage{Text: r.Error.Message + " (" + r.Error.Type + ")"},
+				Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: artifact}}},
+			})
+		}
+
+		for _, finding := range r.Findings {
+			location := sarifPhysicalLocation{ArtifactLocation: artifact}
+			properties := map[string]interface{}{"type": finding.Type}
+			if finding.Location != nil {
+				if finding.Location.FileLine > 0 {
+					location.Region = &sarifRegion{StartLine: finding.Location.FileLine}
+				}
+				if finding.Location.CueIndex > 0 {
+					properties["cueIndex"] = finding.Location.CueIndex
+				}
+				properties["startTime"] = finding.Location.StartTime
+				properties["endTime"] = finding.Location.EndTime
+			}
+			if finding.Details != nil {
+				properties["details"] = finding.Details
+			}
+
+			run.Results = append(run.Results, sarifResult{
+				RuleID:     finding.Rule,
+				RuleIndex:  ruleIndex[finding.Rule],
+				Level:      sarifLevel(finding.Severity),
+				Message:    sarifMessage{Text: finding.Message},
+				Locations:  []sarifLocation{{PhysicalLocation: location}},
+				Properties: properties,
+			})
+		}
+	}
+	run.Invocations = []sarifInvocation{invocation}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+// sarifLevel maps a severity to a SARIF result level
+func sarifLevel(severity validator.Severity) string {
+	switch severity {
+	case validator.SeverityWarning:
+		return "warning"
+	case validator.SeverityInfo:
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+// artifactURI turns a file path into a SARIF URI: relative paths stay
+// relative to the working directory, absolute ones become file URIs
+func artifactURI(path string) string {
+	uri := url.URL{Path: filepath.ToSlash(path)}
+	if filepath.IsAbs(path) {
+		uri.Scheme = "file"
+		if uri.Path[0] != '/' {
+			// Windows drive letters: file:///C:/...
+			uri.Path = "/" + uri.Path
+		}
+	}
+	return uri.String()
+}
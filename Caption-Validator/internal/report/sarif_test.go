@@ -0,0 +1,87 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"caption-validator/internal/parser"
+	"caption-validator/internal/validator"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 0, EndTime: 1, Text: "Far too much text to read in a single second", Line: 4},
+		{Index: 2, StartTime: 1, EndTime: 60, Text: "Fine", Line: 8},
+	}
+	profile := validator.Profile{Rules: []validator.RuleConfig{
+		{Rule: "reading_speed", Severity: "warning", Params: validator.Params{"max_cps": 20.0}},
+		{Rule: "coverage", Params: validator.Params{"min_coverage": 100.0}},
+	}}
+	engine, err := validator.NewEngine(profile)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	checked := New("season 1/episode1.vtt", Params{EndTime: 61, Profile: profile})
+	checked.AddOutcomes(engine.Run(validator.Context{Captions: captions, StartTime: 0, EndTime: 61}))
+	unsupported := New("notes.txt", Params{})
+	unsupported.Fail("unsupported_format", "Unsupported caption file format")
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, []*Report{checked, unsupported}); err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("SARIF output is not valid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF log: %+v", log)
+	}
+	run := log.Runs[0]
+
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ID != "coverage" || run.Tool.Driver.Rules[1].ID != "reading_speed" {
+		t.Errorf("Expected the profile's rules sorted by id, got %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Artifacts) != 2 || run.Artifacts[0].Location.URI != "season%201/episode1.vtt" {
+		t.Errorf("Unexpected artifacts: %+v", run.Artifacts)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %+v", run.Results)
+	}
+
+	speed := run.Results[0]
+	if speed.RuleID != "reading_speed" || speed.RuleIndex != 1 || speed.Level != "warning" {
+		t.Errorf("Unexpected reading speed result: %+v", speed)
+	}
+	if region := speed.Locations[0].PhysicalLocation.Region; region == nil || region.StartLine != 4 {
+		t.Errorf("Expected the reading speed result on line 4, got %+v", region)
+	}
+
+	// Coverage is about the whole range, so it has no region
+	coverage := run.Results[1]
+	if coverage.Level != "error" || coverage.Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("Unexpected coverage result: %+v", coverage)
+	}
+
+	invocation := run.Invocations[0]
+	if invocation.ExecutionSuccessful || len(invocation.Notifications) != 1 ||
+		invocation.Notifications[0].Locations[0].PhysicalLocation.ArtifactLocation.Index != 1 {
+		t.Errorf("Expected the unsupported file as a notification, got %+v", invocation)
+	}
+}
+
+func TestArtifactURI(t *testing.T) {
+	tests := map[string]string{
+		"episode1.vtt":           "episode1.vtt",
+		"season 1/episode#1.srt": "season%201/episode%231.srt",
+		"/media/episode1.vtt":    "file:///media/episode1.vtt",
+	}
+	for path, want := range tests {
+		if got := artifactURI(path); got != want {
+			t.Errorf("artifactURI(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
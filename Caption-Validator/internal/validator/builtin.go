@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// builtinProfiles holds the delivery-spec profiles compiled into the binary
+//
+//go:embed profiles/*.yaml
+var builtinProfiles embed.FS
+
+// BuiltinProfileNames lists the profiles compiled into the binary
+func BuiltinProfileNames() []string {
+	entries, _ := builtinProfiles.ReadDir("profiles")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuiltinProfile returns the profile compiled into the binary under name.
+// Names never contain dots or slashes, so paths such as "./streaming" are not
+// taken for one.
+func BuiltinProfile(name string) (Profile, bool) {
+	if name == "" || strings.ContainsAny(name, `./\`) {
+		return Profile{}, false
+	}
+	data, err := builtinProfiles.ReadFile(path.Join("profiles", name+".yaml"))
+	if err != nil {
+		return Profile{}, false
+	}
+	profile, err := ParseProfile(data)
+	if err != nil {
+		// Built-in profiles are covered by tests, so this is a programming error
+		panic(fmt.Sprintf("validator: invalid built-in profile %s: %v", name, err))
+	}
+	return profile, true
+}
+
+// ResolveProfile returns the built-in profile called nameOrPath if there is
+// one, and otherwise loads the profile file at that path. A name that is both
+// a built-in profile and a file in the working directory is an error rather
+// than a guess; "./name" always means the file.
+func ResolveProfile(nameOrPath string) (Profile, error) {
+	if profile, ok := BuiltinProfile(nameOrPath); ok {
+		if info, err := os.Stat(nameOrPath); err == nil && info.Mode().IsRegular() {
+			return Profile{}, fmt.Errorf("profile %q is ambiguous: it names both a built-in profile and a file (use ./%s for the file)", nameOrPath, nameOrPath)
+		}
+		return profile, nil
+	}
+
+	profile, err := LoadProfile(nameOrPath)
+	if errors.Is(err, fs.ErrNotExist) && !strings.ContainsAny(nameOrPath, `./\`) {
+		return Profile{}, fmt.Errorf("unknown profile %q (built-in profiles: %s)", nameOrPath, strings.Join(BuiltinProfileNames(), ", "))
+	}
+	return profile, err
+}
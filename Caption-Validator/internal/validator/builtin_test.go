@@ -0,0 +1,130 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"caption-validator/internal/parser"
+)
+
+func TestBuiltinProfiles(t *testing.T) {
+	names := BuiltinProfileNames()
+	if strings.Join(names, ",") != "dcmp,ebu,streaming" {
+		t.Fatalf("Unexpected built-in profiles: %v", names)
+	}
+
+	// Every delivery spec sets the full set of limits
+	required := map[string][]string{
+		"reading_speed":      {"max_cps"},
+		"line_length":        {"max_chars_per_line", "max_lines"},
+		"cue_timing":         {"min_duration", "max_duration", "min_gap_frames", "frame_rate"},
+		"allowed_characters": {"charset"},
+	}
+
+	for _, name := range names {
+		profile, ok := BuiltinProfile(name)
+		if !ok {
+			t.Fatalf("BuiltinProfile(%q) not found", name)
+		}
+		if profile.Name != name {
+			t.Errorf("Profile %s is named %q", name, profile.Name)
+		}
+		if _, err := NewEngine(profile); err != nil {
+			t.Errorf("Profile %s does not build: %v", name, err)
+		}
+
+		for rule, params := range required {
+			found := false
+			for _, config := range profile.Rules {
+				if config.Rule != rule {
+					continue
+				}
+				found = true
+				for _, param := range params {
+					if _, ok := config.Params[param]; !ok {
+						t.Errorf("Profile %s rule %s does not set %s", name, rule, param)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("Profile %s has no %s rule", name, rule)
+			}
+		}
+	}
+}
+
+func TestResolveProfile(t *testing.T) {
+	profile, err := ResolveProfile("dcmp")
+	if err != nil || profile.Name != "dcmp" {
+		t.Errorf("ResolveProfile(dcmp) = %+v, %v", profile, err)
+	}
+
+	if _, err := ResolveProfile("fcc"); err == nil || !strings.Contains(err.Error(), "built-in profiles: dcmp, ebu, streaming") {
+		t.Errorf("Expected unknown profile error listing built-ins, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "house.yaml")
+	if err := os.WriteFile(path, []byte("name: house\nrules: [{rule: coverage}]\n"), 0644); err != nil {
+		t.Fatalf("Failed to write profile: %v", err)
+	}
+	if profile, err := ResolveProfile(path); err != nil || profile.Name != "house" {
+		t.Errorf("ResolveProfile(%s) = %+v, %v", path, profile, err)
+	}
+
+	// A local file named like a built-in profile must be asked for by path
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "streaming"), []byte("name: local\nrules: [{rule: coverage}]\n"), 0644); err != nil {
+		t.Fatalf("Failed to write profile: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "ebu"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer os.Chdir(wd)
+	if _, err := ResolveProfile("streaming"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected an ambiguous profile error, got %v", err)
+	}
+	if profile, err := ResolveProfile("./streaming"); err != nil || profile.Name != "local" {
+		t.Errorf("ResolveProfile(./streaming) = %+v, %v", profile, err)
+	}
+	// A directory is not a profile file
+	if profile, err := ResolveProfile("ebu"); err != nil || profile.Name != "ebu" {
+		t.Errorf("ResolveProfile(ebu) = %+v, %v", profile, err)
+	}
+}
+
+func TestDCMPProfile(t *testing.T) {
+	profile, _ := BuiltinProfile("dcmp")
+	engine, err := NewEngine(profile)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 0, EndTime: 3, Text: "A caption that is well within\nthe limits."},
+		{Index: 2, StartTime: 3.1, EndTime: 6, Text: "This line is longer than thirty-two columns"},
+		{Index: 3, StartTime: 6.1, EndTime: 6.5, Text: "Quick"},
+		{Index: 4, StartTime: 7, EndTime: 10, Text: "Emoji 😀 is not CEA-608"},
+	}
+	outcomes := engine.Run(Context{Captions: captions, Format: parser.FormatSRT, StartTime: 0, EndTime: 10})
+
+	failed := make(map[string]bool)
+	for _, outcome := range outcomes {
+		if outcome.Err == nil && !outcome.Result.Valid {
+			failed[outcome.Result.Type] = true
+		}
+	}
+	for _, want := range []string{"line_length", "cue_timing", "disallowed_characters"} {
+		if !failed[want] {
+			t.Errorf("Expected %s failure from dcmp profile, got %v", want, failed)
+		}
+	}
+}
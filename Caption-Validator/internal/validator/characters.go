@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"caption-validator/internal/parser"
+)
+
+// charsets are the named character sets the allowed_characters rule accepts
+var charsets = map[string]func(rune) bool{
+	// Characters CEA-608 decoders can display (US broadcast, SCC)
+	"cea608": parser.IsCEA608Character,
+	// Printable ASCII only
+	"ascii": func(r rune) bool {
+		return r >= 0x20 && r < 0x7F
+	},
+	// Latin script subtitles: ASCII, Latin-1, Latin Extended-A, common
+	// typographic punctuation and music notes
+	"latin": func(r rune) bool {
+		switch {
+		case r >= 0x20 && r < 0x7F, r >= 0xA0 && r <= 0x17F:
+			return true
+		case r >= 0x2010 && r <= 0x2026: // dashes, quotes, bullets, ellipsis
+			return true
+		}
+		return strings.ContainsRune("€♪♫", r)
+	},
+}
+
+// CharacterFinding describes a caption containing characters outside the allowed set
+type CharacterFinding struct {
+	Index      int      `json:"index"`
+	StartTime  float64  `json:"start_time"`
+	EndTime    float64  `json:"end_time"`
+	Text       string   `json:"text"`
+	Characters []string `json:"characters"`
+	CodePoints []string `json:"code_points"`
+}
+
+// ValidateCharacters reports every caption that uses a character for which
+// allowed returns false. Line breaks are always allowed and markup tags are
+// ignored.
+func ValidateCharacters(captions []parser.Caption, allowed func(rune) bool) ValidationResult {
+	findings := []CharacterFinding{}
+	var located []Finding
+
+	for _, caption := range captions {
+		text := caption.PlainText()
+		seen := make(map[rune]bool)
+		for _, r := range text {
+			if r == '\n' || r == '\r' || allowed(r) {
+				continue
+			}
+			seen[r] = true
+		}
+		if len(seen) == 0 {
+			continue
+		}
+
+		disallowed := make([]rune, 0, len(seen))
+		for r := range seen {
+			disallowed = append(disallowed, r)
+		}
+		sort.Slice(disallowed, func(i, j int) bool { return disallowed[i] < disallowed[j] })
+
+		finding := CharacterFinding{
+			Index:     caption.Index,
+			StartTime: caption.StartTime,
+			EndTime:   caption.EndTime,
+			Text:      text,
+		}
+		for _, r := range disallowed {
+			character := string(r)
+			if !unicode.IsPrint(r) {
+				character = fmt.Sprintf("%q", r)
+			}
+			finding.Characters = append(finding.Characters, character)
+			finding.CodePoints = append(finding.CodePoints, fmt.Sprintf("U+%04X", r))
+		}
+		listed := make([]string, len(finding.Characters))
+		for i := range finding.Characters {
+			listed[i] = fmt.Sprintf("%s (%s)", finding.Characters[i], finding.CodePoints[i])
+		}
+		findings = append(findings, finding)
+		located = append(located, Finding{
+			Message:  "Caption contains characters that are not allowed: " + strings.Join(listed, ", "),
+			Location: cueLocation(caption),
+			Details:  finding,
+		})
+	}
+
+	return ValidationResult{
+		Valid:    len(findings) == 0,
+		Findings: located,
+		Type:     "disallowed_characters",
+		Data: map[string]interface{}{
+			"captions": findings,
+		},
+	}
+}
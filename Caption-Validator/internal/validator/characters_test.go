@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"caption-validator/internal/parser"
+)
+
+func TestValidateCharacters(t *testing.T) {
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 0, EndTime: 2, Text: "<i>Café ♪</i>\n¿Qué?"},
+		{Index: 2, StartTime: 2, EndTime: 4, Text: "Smart “quotes” and ☃☃"},
+		{Index: 3, StartTime: 4, EndTime: 6, Text: "日本語"},
+	}
+
+	tests := []struct {
+		charset string
+		extra   string
+		want    []int
+	}{
+		{"cea608", "", []int{2, 3}},
+		{"latin", "", []int{2, 3}},
+		{"latin", "☃", []int{3}},
+		{"ascii", "", []int{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		rule, err := NewRule("allowed_characters", Params{"charset": tt.charset, "extra": tt.extra})
+		if err != nil {
+			t.Fatalf("NewRule() error = %v", err)
+		}
+		result, err := rule.Check(Context{Captions: captions})
+		if err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+
+		findings := result.Data["captions"].([]CharacterFinding)
+		var got []int
+		for _, finding := range findings {
+			got = append(got, finding.Index)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s+%q: findings for captions %v, want %v", tt.charset, tt.extra, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s+%q: findings for captions %v, want %v", tt.charset, tt.extra, got, tt.want)
+				break
+			}
+		}
+	}
+
+	result := ValidateCharacters(captions[1:2], charsets["cea608"])
+	var jsonObj struct {
+		Captions []CharacterFinding `json:"captions"`
+	}
+	if err := json.Unmarshal([]byte(result.JSON()), &jsonObj); err != nil {
+		t.Fatalf("Failed to parse JSON result: %v", err)
+	}
+	// Curly quotes are in the CEA-608 extended set; the snowman is listed once
+	finding := jsonObj.Captions[0]
+	if len(finding.Characters) != 1 || finding.Characters[0] != "☃" || finding.CodePoints[0] != "U+2603" {
+		t.Errorf("Unexpected finding: %+v", finding)
+	}
+
+	if _, err := NewRule("allowed_characters", Params{"charset": "klingon"}); err == nil {
+		t.Error("Expected error for unknown charset")
+	}
+}
@@ -4,15 +4,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"sort"
 
 	"caption-validator/internal/parser"
 )
 
 // ValidationResult represents the result of a validation check
 type ValidationResult struct {
-	Valid bool
-	Type  string
-	Data  map[string]interface{}
+	Valid    bool
+	Type     string
+	Severity Severity // set by the Engine from the profile
+	Data     map[string]interface{}
+	Findings []Finding // one entry per problem when Valid is false
 }
 
 // JSON returns the JSON representation of the validation result
@@ -20,6 +23,9 @@ func (vr ValidationResult) JSON() string {
 	result := map[string]interface{}{
 		"type": vr.Type,
 	}
+	if vr.Severity != "" {
+		result["severity"] = vr.Severity
+	}
 	
 	// Add all other fields from Data
 	for k, v := range vr.Data {
@@ -34,21 +40,23 @@ func (vr ValidationResult) JSON() string {
 	return string(jsonBytes)
 }
 
-// ValidateCoverage checks if captions cover the required percentage of time
-func ValidateCoverage(captions []parser.Caption, startTime float64, endTime float64, minCoverage float64) (ValidationResult, error) {
-	if endTime <= startTime {
-		return ValidationResult{}, fmt.Errorf("end time must be greater than start time")
-	}
-	
-	// Calculate total time range
-	totalTime := endTime - startTime
-	
-	// Track covered time segments
-	type timeSegment struct {
-		start float64
-		end   float64
-	}
-	var coveredSegments []timeSegment
+// Gap is an interval within the validated range that no caption covers
+type Gap struct {
+	Start    float64 `json:"start"`
+	End      float64 `json:"end"`
+	Duration float64 `json:"duration"`
+}
+
+// timeSegment is a covered interval, in seconds
+type timeSegment struct {
+	start float64
+	end   float64
+}
+
+// coveredSegments clips the captions to [startTime, endTime] and merges
+// overlapping ones, returning the covered intervals sorted by start time
+func coveredSegments(captions []parser.Caption, startTime float64, endTime float64) []timeSegment {
+	var segments []timeSegment
 	
 	// Add all caption time segments that overlap with our range
 	for _, caption := range captions {
@@ -61,43 +69,89 @@ func ValidateCoverage(captions []parser.Caption, startTime float64, endTime floa
 		segStart := math.Max(caption.StartTime, startTime)
 		segEnd := math.Min(caption.EndTime, endTime)
 		
-		coveredSegments = append(coveredSegments, timeSegment{segStart, segEnd})
+		segments = append(segments, timeSegment{segStart, segEnd})
+	}
+	
+	if len(segments) == 0 {
+		return nil
 	}
 	
+	// Sort segments by start time
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].start < segments[j].start
+	})
+	
 	// Merge overlapping segments
-	if len(coveredSegments) > 0 {
-		// Sort segments by start time
-		for i := 0; i < len(coveredSegments)-1; i++ {
-			for j := i + 1; j < len(coveredSegments); j++ {
-				if coveredSegments[i].start > coveredSegments[j].start {
-					coveredSegments[i], coveredSegments[j] = coveredSegments[j], coveredSegments[i]
-				}
-			}
-		}
+	merged := []timeSegment{segments[0]}
+	for i := 1; i < len(segments); i++ {
+		last := &merged[len(merged)-1]
+		current := segments[i]
 		
-		// Merge overlapping segments
-		merged := []timeSegment{coveredSegments[0]}
-		for i := 1; i < len(coveredSegments); i++ {
-			last := &merged[len(merged)-1]
-			current := coveredSegments[i]
-			
-			// If current segment overlaps with last merged segment, extend last segment
-			if current.start <= last.end {
-				if current.end > last.end {
-					last.end = current.end
-				}
-			} else {
-				// No overlap, add as new segment
-				merged = append(merged, current)
+		// If current segment overlaps with last merged segment, extend last segment
+		if current.start <= last.end {
+			if current.end > last.end {
+				last.end = current.end
 			}
+		} else {
+			// No overlap, add as new segment
+			merged = append(merged, current)
 		}
-		
-		coveredSegments = merged
 	}
 	
+	return merged
+}
+
+// uncoveredGaps returns the intervals of [startTime, endTime] not covered by segments
+func uncoveredGaps(segments []timeSegment, startTime float64, endTime float64) []Gap {
+	gaps := []Gap{}
+	cursor := startTime
+	
+	for _, seg := range segments {
+		if seg.start > cursor {
+			gaps = append(gaps, newGap(cursor, seg.start))
+		}
+		cursor = math.Max(cursor, seg.end)
+	}
+	if cursor < endTime {
+		gaps = append(gaps, newGap(cursor, endTime))
+	}
+	
+	return gaps
+}
+
+// newGap builds a Gap rounded to milliseconds
+func newGap(start float64, end float64) Gap {
+	return Gap{
+		Start:    roundMillis(start),
+		End:      roundMillis(end),
+		Duration: roundMillis(end - start),
+	}
+}
+
+// FindGaps returns every interval within [startTime, endTime] that no caption covers
+func FindGaps(captions []parser.Caption, startTime float64, endTime float64) ([]Gap, error) {
+	if endTime <= startTime {
+		return nil, fmt.Errorf("end time must be greater than start time")
+	}
+	
+	return uncoveredGaps(coveredSegments(captions, startTime, endTime), startTime, endTime), nil
+}
+
+// ValidateCoverage checks if captions cover the required percentage of time.
+// The result lists every uncovered interval under "gaps".
+func ValidateCoverage(captions []parser.Caption, startTime float64, endTime float64, minCoverage float64) (ValidationResult, error) {
+	if endTime <= startTime {
+		return ValidationResult{}, fmt.Errorf("end time must be greater than start time")
+	}
+	
+	// Calculate total time range
+	totalTime := endTime - startTime
+	
+	segments := coveredSegments(captions, startTime, endTime)
+	
 	// Calculate total covered time
 	coveredTime := 0.0
-	for _, seg := range coveredSegments {
+	for _, seg := range segments {
 		coveredTime += seg.end - seg.start
 	}
 	
@@ -117,11 +171,18 @@ func ValidateCoverage(captions []parser.Caption, startTime float64, endTime floa
 			"end_time":          endTime,
 			"covered_time":      math.Round(coveredTime*100) / 100,
 			"total_time":        totalTime,
+			"gaps":              uncoveredGaps(segments, startTime, endTime),
 		},
 	}
 	
 	if !valid {
 		result.Data["missing_coverage_seconds"] = math.Round(((minCoverage/100)*totalTime-coveredTime)*100) / 100
+		result.Findings = []Finding{{
+			Message: fmt.Sprintf("Captions cover %.2f%% of the validated range, below the required %g%%",
+				result.Data["actual_coverage"], minCoverage),
+			Location: &Location{StartTime: startTime, EndTime: endTime},
+			Details:  result.Data["gaps"],
+		}}
 	}
 	
 	return result, nil
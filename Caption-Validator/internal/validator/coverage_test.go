@@ -110,3 +110,43 @@ func TestValidateCoverage(t *testing.T) {
 		}
 	})
 }
+
+func TestFindGaps(t *testing.T) {
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 10.0, EndTime: 20.0, Text: "Caption 1"},
+		{Index: 2, StartTime: 18.0, EndTime: 25.0, Text: "Overlaps caption 1"},
+		{Index: 3, StartTime: 40.0, EndTime: 55.0, Text: "Caption 3"},
+	}
+
+	gaps, err := FindGaps(captions, 5.0, 60.0)
+	if err != nil {
+		t.Fatalf("FindGaps() error = %v", err)
+	}
+
+	want := []Gap{
+		{Start: 5, End: 10, Duration: 5},
+		{Start: 25, End: 40, Duration: 15},
+		{Start: 55, End: 60, Duration: 5},
+	}
+	if len(gaps) != len(want) {
+		t.Fatalf("FindGaps() returned %d gaps, want %d: %+v", len(gaps), len(want), gaps)
+	}
+	for i := range want {
+		if gaps[i] != want[i] {
+			t.Errorf("Gap %d = %+v, want %+v", i, gaps[i], want[i])
+		}
+	}
+
+	// Fully covered range has no gaps, and the coverage result still lists them (as empty)
+	result, err := ValidateCoverage(captions, 10.0, 25.0, 95.0)
+	if err != nil {
+		t.Fatalf("ValidateCoverage() error = %v", err)
+	}
+	var jsonObj map[string]interface{}
+	if err := json.Unmarshal([]byte(result.JSON()), &jsonObj); err != nil {
+		t.Fatalf("Failed to parse JSON result: %v", err)
+	}
+	if listed, ok := jsonObj["gaps"].([]interface{}); !ok || len(listed) != 0 {
+		t.Errorf("Expected an empty gaps list, got %v", jsonObj["gaps"])
+	}
+}
@@ -0,0 +1,32 @@
+package validator
+
+import "caption-validator/internal/parser"
+
+// Location points at the part of a caption file a finding is about
+type Location struct {
+	CueIndex  int     `json:"cue_index,omitempty"` // Caption.Index of the cue, if the finding is about one
+	Line      int     `json:"line,omitempty"`      // 1-based line within the cue text
+	FileLine  int     `json:"file_line,omitempty"` // 1-based line of the cue in the caption file, if known; since report schema 1.1
+	StartTime float64 `json:"start_time"`          // seconds
+	EndTime   float64 `json:"end_time"`            // seconds
+}
+
+// Finding is one problem reported by a failed validation, such as a single
+// cue that is too fast to read. Details holds the rule-specific record for
+// the problem (e.g. a ReadingSpeed) and is serialised as-is.
+type Finding struct {
+	Message  string      `json:"message"`
+	Location *Location   `json:"location,omitempty"`
+	Details  interface{} `json:"details,omitempty"`
+}
+
+// cueLocation locates a finding at a cue. The file line is the parser's
+// Caption.Line, which points at the cue's timing.
+func cueLocation(caption parser.Caption) *Location {
+	return &Location{
+		CueIndex:  caption.Index,
+		FileLine:  caption.Line,
+		StartTime: caption.StartTime,
+		EndTime:   caption.EndTime,
+	}
+}
@@ -0,0 +1,50 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+
+	"caption-validator/internal/parser"
+)
+
+// ValidateMaxGap checks that no single uncaptioned interval within
+// [startTime, endTime] is longer than maxGap seconds. It fails on one long
+// hole even when overall coverage is acceptable.
+func ValidateMaxGap(captions []parser.Caption, startTime float64, endTime float64, maxGap float64) (ValidationResult, error) {
+	if maxGap <= 0 {
+		return ValidationResult{}, fmt.Errorf("max gap must be greater than zero")
+	}
+
+	gaps, err := FindGaps(captions, startTime, endTime)
+	if err != nil {
+		return ValidationResult{}, err
+	}
+
+	longest := 0.0
+	exceeding := []Gap{}
+	var findings []Finding
+	for _, gap := range gaps {
+		longest = math.Max(longest, gap.Duration)
+		if gap.Duration > maxGap {
+			exceeding = append(exceeding, gap)
+			findings = append(findings, Finding{
+				Message:  fmt.Sprintf("No captions for %.3f seconds, longer than the %g second limit", gap.Duration, maxGap),
+				Location: &Location{StartTime: gap.Start, EndTime: gap.End},
+				Details:  gap,
+			})
+		}
+	}
+
+	return ValidationResult{
+		Valid:    len(exceeding) == 0,
+		Findings: findings,
+		Type:     "caption_gap",
+		Data: map[string]interface{}{
+			"max_gap":     maxGap,
+			"longest_gap": longest,
+			"start_time":  startTime,
+			"end_time":    endTime,
+			"gaps":        exceeding,
+		},
+	}, nil
+}
@@ -0,0 +1,53 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"caption-validator/internal/parser"
+)
+
+func TestValidateMaxGap(t *testing.T) {
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 0.0, EndTime: 20.0, Text: "Caption 1"},
+		{Index: 2, StartTime: 22.0, EndTime: 50.0, Text: "Caption 2"},
+		{Index: 3, StartTime: 58.0, EndTime: 60.0, Text: "Caption 3"},
+	}
+
+	// Coverage is 83%, but the 8 second hole fails a 5 second limit
+	coverage, err := ValidateCoverage(captions, 0, 60, 80)
+	if err != nil || !coverage.Valid {
+		t.Fatalf("Expected coverage to pass, got %+v, %v", coverage, err)
+	}
+
+	result, err := ValidateMaxGap(captions, 0, 60, 5)
+	if err != nil {
+		t.Fatalf("ValidateMaxGap() error = %v", err)
+	}
+	if result.Valid || result.Type != "caption_gap" {
+		t.Errorf("Expected caption_gap failure, got %+v", result)
+	}
+
+	var jsonObj struct {
+		LongestGap float64 `json:"longest_gap"`
+		Gaps       []Gap   `json:"gaps"`
+	}
+	if err := json.Unmarshal([]byte(result.JSON()), &jsonObj); err != nil {
+		t.Fatalf("Failed to parse JSON result: %v", err)
+	}
+	if jsonObj.LongestGap != 8 || len(jsonObj.Gaps) != 1 || jsonObj.Gaps[0].Start != 50 || jsonObj.Gaps[0].End != 58 {
+		t.Errorf("Unexpected gap report: %+v", jsonObj)
+	}
+	if len(result.Findings) != 1 || result.Findings[0].Location.StartTime != 50 || result.Findings[0].Location.CueIndex != 0 {
+		t.Errorf("Expected one finding located at the gap, got %+v", result.Findings)
+	}
+
+	result, err = ValidateMaxGap(captions, 0, 60, 8)
+	if err != nil || !result.Valid {
+		t.Errorf("Expected an 8 second gap to pass an 8 second limit, got %+v, %v", result, err)
+	}
+
+	if _, err := ValidateMaxGap(captions, 0, 60, 0); err == nil {
+		t.Error("Expected error for non-positive max gap")
+	}
+}
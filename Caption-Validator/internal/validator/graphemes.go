@@ -0,0 +1,109 @@
+package validator
+
+import "unicode"
+
+// graphemeCount returns the number of user-perceived characters in s. It
+// follows the main extended grapheme cluster rules of Unicode UAX #29:
+// combining marks, variation selectors, emoji modifiers and zero width joiner
+// sequences attach to the preceding character, regional indicators pair into
+// flags and Hangul jamo combine into syllables. Precomposed and CJK characters
+// count as one each, as they appear on screen.
+func graphemeCount(s string) int {
+	count := 0
+	var prev rune = -1
+	riRun := 0 // regional indicators seen in the current run
+
+	for _, r := range s {
+		if prev >= 0 && joinsPrevious(prev, r, riRun) {
+			if isRegionalIndicator(r) {
+				riRun++
+			}
+			prev = r
+			continue
+		}
+
+		count++
+		riRun = 0
+		if isRegionalIndicator(r) {
+			riRun = 1
+		}
+		prev = r
+	}
+
+	return count
+}
+
+// joinsPrevious reports whether r continues the grapheme cluster ending in prev
+func joinsPrevious(prev rune, r rune, riRun int) bool {
+	switch {
+	case prev == '\r' && r == '\n':
+		return true
+	case prev == '\r' || prev == '\n' || r == '\r' || r == '\n':
+		return false
+	case isGraphemeExtend(r):
+		return true
+	case prev == '\u200d' && (unicode.Is(unicode.So, r) || isEmojiModifier(r)):
+		return true
+	case isRegionalIndicator(prev) && isRegionalIndicator(r):
+		return riRun%2 == 1
+	}
+	return hangulJoins(prev, r)
+}
+
+// isGraphemeExtend reports whether r never starts a cluster of its own
+func isGraphemeExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r == '\u200c' || r == '\u200d' ||
+		(r >= 0xFE00 && r <= 0xFE0F) || (r >= 0xE0100 && r <= 0xE01EF) ||
+		(r >= 0xE0020 && r <= 0xE007F) ||
+		isEmojiModifier(r)
+}
+
+func isEmojiModifier(r rune) bool {
+	return r >= 0x1F3FB && r <= 0x1F3FF
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// Hangul syllable types from UAX #29
+const (
+	hangulNone = iota
+	hangulL
+	hangulV
+	hangulT
+	hangulLV
+	hangulLVT
+)
+
+func hangulType(r rune) int {
+	switch {
+	case (r >= 0x1100 && r <= 0x115F) || (r >= 0xA960 && r <= 0xA97C):
+		return hangulL
+	case (r >= 0x1160 && r <= 0x11A7) || (r >= 0xD7B0 && r <= 0xD7C6):
+		return hangulV
+	case (r >= 0x11A8 && r <= 0x11FF) || (r >= 0xD7CB && r <= 0xD7FB):
+		return hangulT
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return hangulLV
+		}
+		return hangulLVT
+	}
+	return hangulNone
+}
+
+// hangulJoins applies the Hangul syllable sequence rules GB6 to GB8
+func hangulJoins(prev rune, r rune) bool {
+	p, n := hangulType(prev), hangulType(r)
+	switch p {
+	case hangulL:
+		return n == hangulL || n == hangulV || n == hangulLV || n == hangulLVT
+	case hangulLV, hangulV:
+		return n == hangulV || n == hangulT
+	case hangulLVT, hangulT:
+		return n == hangulT
+	}
+	return false
+}
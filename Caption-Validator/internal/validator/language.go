@@ -0,0 +1,264 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"caption-validator/internal/client"
+	"caption-validator/internal/parser"
+)
+
+// allowedMarkup matches the spans of cue text that mark dialogue as
+// intentionally in another language, by the name used in the language
+// rule's allow parameter. Unclosed spans run to the end of the cue, as in
+// WebVTT.
+var allowedMarkup = map[string]*regexp.Regexp{
+	// <i>...</i> in WebVTT and SRT, and {\i1}...{\i0} in SRT
+	"italics": regexp.MustCompile(`(?is)<i(?:[.\s][^>]*)?>.*?(?:</i>|$)|\{\\i1\}.*?(?:\{\\i0\}|$)`),
+	// WebVTT <c.foreign>...</c> and <lang xx>...</lang> spans
+	"foreign": regexp.MustCompile(`(?is)<c\.[^>\s]*\bforeign\b[^>]*>.*?(?:</c>|$)|<lang\s[^>]*>.*?(?:</lang>|$)`),
+}
+
+// AllowedMarkupNames lists the values of the language rule's allow parameter
+func AllowedMarkupNames() []string {
+	names := make([]string, 0, len(allowedMarkup))
+	for name := range allowedMarkup {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// undeterminedLanguage is the BCP 47 tag detectors report for text whose
+// language they cannot tell, which is never a mismatch
+const undeterminedLanguage = "und"
+
+// LanguageText returns the plain text of a caption without the spans marked
+// by the allowed markup, which are not expected to be in the track's language
+func LanguageText(caption parser.Caption, allow []string) string {
+	text := caption.Text
+	for _, name := range allow {
+		if pattern, ok := allowedMarkup[name]; ok {
+			text = pattern.ReplaceAllString(text, " ")
+		}
+	}
+	return strings.TrimSpace(parser.Caption{Text: text}.PlainText())
+}
+
+// LanguageSegmentOptions configures ValidateLanguageSegments
+type LanguageSegmentOptions struct {
+	Expected string               // BCP 47 tag the captions should be in
+	Match    client.LanguageMatch // how closely a detected language must match
+	// AllowLanguages are accepted in any segment besides Expected
+	AllowLanguages []string
+	// Allow names the markup whose text is not checked, see AllowedMarkupNames
+	Allow []string
+	// Window groups the cues starting within this many seconds into one
+	// segment, with a new window every half window so that each cue is
+	// checked in two of them; 0 checks every cue on its own
+	Window float64
+	// MinLetters skips segments with fewer letters, which detectors cannot
+	// identify reliably
+	MinLetters int
+	// MinConfidence ignores mismatches the detector is less sure of. Detectors
+	// that report no confidence are always trusted.
+	MinConfidence float64
+}
+
+// LanguageRange is a stretch of consecutive segments detected in a language
+// other than the expected one
+type LanguageRange struct {
+	FirstIndex int     `json:"first_index"`
+	LastIndex  int     `json:"last_index"`
+	StartTime  float64 `json:"start_time"`
+	EndTime    float64 `json:"end_time"`
+	Detected   string  `json:"detected"`
+	Confidence float64 `json:"confidence,omitempty"` // the lowest of its segments
+	Text       string  `json:"text"`
+	fileLine   int
+}
+
+// languageCue is a caption with the text the language rule checks
+type languageCue struct {
+	caption parser.Caption
+	text    string
+}
+
+// languageSegment is one cue or window: the cues from and to (inclusive) of
+// the checked cues
+type languageSegment struct {
+	from, to int
+}
+
+// ValidateLanguageSegments detects the language of every cue, or of every
+// window of cues, with validate and reports the time ranges detected in a
+// language other than the expected one. Consecutive segments in the same
+// wrong language are reported as one range.
+func ValidateLanguageSegments(captions []parser.Caption, opts LanguageSegmentOptions, validate func(text string, expectedLang string) (client.LanguageValidationResult, error)) (ValidationResult, error) {
+	if opts.Window < 0 {
+		return ValidationResult{}, fmt.Errorf("language window must not be negative")
+	}
+
+	ranges := []LanguageRange{}
+	checked := 0
+	var open *LanguageRange
+	openTo := 0 // the last cue of the open range
+	closeRange := func() {
+		if open != nil {
+			ranges = append(ranges, *open)
+			open = nil
+		}
+	}
+
+	cues := languageCues(captions, opts.Allow)
+	for _, segment := range splitLanguageSegments(cues, opts.Window) {
+		text := segmentText(cues, segment.from, segment.to)
+		if countLetters(text) < opts.MinLetters {
+			continue
+		}
+		checked++
+
+		result, err := validate(text, opts.Expected)
+		if err != nil {
+			return ValidationResult{}, err
+		}
+		if languageAccepted(result.Language, opts) || result.Language == undeterminedLanguage ||
+			(result.Confidence > 0 && result.Confidence < opts.MinConfidence) {
+			closeRange()
+			continue
+		}
+
+		first, last := cues[segment.from].caption, cues[segment.to].caption
+		if open != nil && open.Detected == result.Language {
+			// Overlapping windows share cues, whose text is added once
+			if from := max(segment.from, openTo+1); from <= segment.to {
+				open.Text += "\n" + segmentText(cues, from, segment.to)
+			}
+			open.LastIndex = last.Index
+			open.EndTime = last.EndTime
+			open.Confidence = math.Min(open.Confidence, result.Confidence)
+			openTo = segment.to
+			continue
+		}
+		closeRange()
+		open = &LanguageRange{
+			FirstIndex: first.Index,
+			LastIndex:  last.Index,
+			StartTime:  first.StartTime,
+			EndTime:    last.EndTime,
+			Detected:   result.Language,
+			Confidence: result.Confidence,
+			Text:       text,
+			fileLine:   first.Line,
+		}
+		openTo = segment.to
+	}
+	closeRange()
+
+	var findings []Finding
+	for _, r := range ranges {
+		findings = append(findings, Finding{
+			Message: fmt.Sprintf("Captions from %.3f to %.3f seconds were detected as %s, expected %s",
+				r.StartTime, r.EndTime, r.Detected, opts.Expected),
+			Location: &Location{CueIndex: r.FirstIndex, FileLine: r.fileLine, StartTime: r.StartTime, EndTime: r.EndTime},
+			Details:  r,
+		})
+	}
+
+	return ValidationResult{
+		Valid:    len(ranges) == 0,
+		Findings: findings,
+		Type:     "incorrect_language",
+		Data: map[string]interface{}{
+			"expected": opts.Expected,
+			"match":    string(opts.Match),
+			"segments": checked,
+			"ranges":   ranges,
+		},
+	}, nil
+}
+
+// languageCues returns the captions with text left to check once the allowed
+// markup is removed
+func languageCues(captions []parser.Caption, allow []string) []languageCue {
+	var cues []languageCue
+	for _, caption := range captions {
+		if text := LanguageText(caption, allow); text != "" {
+			cues = append(cues, languageCue{caption: caption, text: text})
+		}
+	}
+	return cues
+}
+
+// splitLanguageSegments groups the cues into segments of one cue each, or
+// into windows of window seconds starting every half window. A foreign
+// passage that straddles the edge of one window then falls in the middle of
+// the next. Windows holding no cue that the one before did not are left out.
+func splitLanguageSegments(cues []languageCue, window float64) []languageSegment {
+	var segments []languageSegment
+	if window <= 0 {
+		for i := range cues {
+			segments = append(segments, languageSegment{from: i, to: i})
+		}
+		return segments
+	}
+
+	step := window / 2
+	from, to := 0, -1
+	for from < len(cues) {
+		// Start the window at the last half window boundary before the
+		// first cue it must hold, skipping any stretch without captions
+		start := math.Floor(cues[from].caption.StartTime/step) * step
+		end := start + window
+		next := from + 1
+		for next < len(cues) && cues[next].caption.StartTime < end {
+			next++
+		}
+		if next-1 > to {
+			segments = append(segments, languageSegment{from: from, to: next - 1})
+			to = next - 1
+		}
+		from++
+		for from < len(cues) && cues[from].caption.StartTime < start+step {
+			from++
+		}
+	}
+	return segments
+}
+
+// segmentText joins the text of the cues from and to (inclusive)
+func segmentText(cues []languageCue, from, to int) string {
+	texts := make([]string, 0, to-from+1)
+	for _, cue := range cues[from : to+1] {
+		texts = append(texts, cue.text)
+	}
+	return strings.Join(texts, " ")
+}
+
+// languageAccepted reports whether a detected language is the expected one
+// or an allowed one
+func languageAccepted(detected string, opts LanguageSegmentOptions) bool {
+	if opts.Match.Matches(detected, opts.Expected) {
+		return true
+	}
+	for _, allowed := range opts.AllowLanguages {
+		if opts.Match.Matches(detected, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func countLetters(text string) int {
+	letters := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return letters
+}
@@ -0,0 +1,274 @@
+package validator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"caption-validator/internal/client"
+	"caption-validator/internal/parser"
+)
+
+// wordDetector detects French in text containing "bonjour", German in text
+// containing "hallo" and English otherwise, counting the calls it gets
+type wordDetector struct {
+	calls int
+}
+
+func (d *wordDetector) validate(text string, expected string) (client.LanguageValidationResult, error) {
+	d.calls++
+	language := "en"
+	switch {
+	case strings.Contains(strings.ToLower(text), "bonjour"):
+		language = "fr"
+	case strings.Contains(strings.ToLower(text), "hallo"):
+		language = "de"
+	}
+	return client.LanguageValidationResult{Language: language, ExpectedLang: expected, Confidence: 0.9}, nil
+}
+
+func TestLanguageText(t *testing.T) {
+	tests := []struct {
+		text  string
+		allow []string
+		want  string
+	}{
+		{"<i>Bonjour</i> said the man", nil, "Bonjour said the man"},
+		{"<i>Bonjour</i> said the man", []string{"italics"}, "said the man"},
+		{"{\\i1}Bonjour{\\i0} said the man", []string{"italics"}, "said the man"},
+		{"<i>Bonjour, tout le monde", []string{"italics"}, ""},
+		{"He said <c.foreign>bonjour</c> to me", []string{"foreign"}, "He said   to me"},
+		{"He said <c.yellow.foreign>bonjour</c> to me", []string{"foreign"}, "He said   to me"},
+		{"<lang fr>Bonjour</lang>, he said", []string{"foreign"}, ", he said"},
+		{"<c.foreign>Bonjour</c> and <i>hello</i>", []string{"foreign"}, "and hello"},
+		{"<c.yellow>Bonjour</c>", []string{"foreign"}, "Bonjour"},
+		{"<b>Bold</b> text", []string{"italics", "foreign"}, "Bold text"},
+	}
+	for _, tt := range tests {
+		if got := LanguageText(parser.Caption{Text: tt.text}, tt.allow); got != tt.want {
+			t.Errorf("LanguageText(%q, %v) = %q, want %q", tt.text, tt.allow, got, tt.want)
+		}
+	}
+}
+
+func TestValidateLanguageSegments(t *testing.T) {
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 0, EndTime: 4, Text: "Where are you going tonight?", Line: 3},
+		{Index: 2, StartTime: 5, EndTime: 9, Text: "Bonjour, je vais au marché.", Line: 6},
+		{Index: 3, StartTime: 10, EndTime: 14, Text: "Bonjour encore, mon ami.", Line: 9},
+		{Index: 4, StartTime: 15, EndTime: 19, Text: "Right, see you there then.", Line: 12},
+		{Index: 5, StartTime: 20, EndTime: 24, Text: "<i>Bonjour, monsieur le directeur</i>", Line: 15},
+		{Index: 6, StartTime: 25, EndTime: 29, Text: "Hallo, wie geht es dir heute?", Line: 18},
+		{Index: 7, StartTime: 30, EndTime: 34, Text: "Okay.", Line: 21},
+	}
+
+	t.Run("Per cue", func(t *testing.T) {
+		detector := &wordDetector{}
+		result, err := ValidateLanguageSegments(captions, LanguageSegmentOptions{
+			Expected:   "en",
+			Match:      client.MatchPrefix,
+			Allow:      []string{"italics"},
+			MinLetters: 15,
+		}, detector.validate)
+		if err != nil {
+			t.Fatalf("ValidateLanguageSegments() error = %v", err)
+		}
+
+		// Cues 2 and 3 are merged, the italic cue 5 is allowed and the short
+		// cue 7 is not checked
+		if result.Valid || len(result.Findings) != 2 || detector.calls != 5 || result.Data["segments"] != 5 {
+			t.Fatalf("Unexpected result after %d calls: %+v", detector.calls, result)
+		}
+		french := result.Findings[0].Details.(LanguageRange)
+		if french.FirstIndex != 2 || french.LastIndex != 3 || french.StartTime != 5 || french.EndTime != 14 || french.Detected != "fr" {
+			t.Errorf("Unexpected French range: %+v", french)
+		}
+		if loc := result.Findings[0].Location; loc.CueIndex != 2 || loc.FileLine != 6 || loc.EndTime != 14 {
+			t.Errorf("Unexpected location: %+v", loc)
+		}
+		if german := result.Findings[1].Details.(LanguageRange); german.FirstIndex != 6 || german.Detected != "de" {
+			t.Errorf("Unexpected German range: %+v", german)
+		}
+	})
+
+	t.Run("Allowed languages", func(t *testing.T) {
+		detector := &wordDetector{}
+		result, err := ValidateLanguageSegments(captions, LanguageSegmentOptions{
+			Expected:       "en-US",
+			Match:          client.MatchLanguage,
+			AllowLanguages: []string{"fr"},
+			Allow:          []string{"italics"},
+		}, detector.validate)
+		if err != nil || len(result.Findings) != 1 || result.Findings[0].Details.(LanguageRange).Detected != "de" {
+			t.Errorf("Expected only the German cue, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("Per window", func(t *testing.T) {
+		detector := &wordDetector{}
+		result, err := ValidateLanguageSegments(captions, LanguageSegmentOptions{
+			Expected: "en",
+			Match:    client.MatchPrefix,
+			Window:   10,
+		}, detector.validate)
+		if err != nil {
+			t.Fatalf("ValidateLanguageSegments() error = %v", err)
+		}
+		// Windows start every 5 seconds. Those up to [20,30) contain French,
+		// the italic cue included, [25,35) is German, and [30,40) holds no
+		// cue that [25,35) did not, so it is not checked.
+		if detector.calls != 6 || len(result.Findings) != 2 {
+			t.Fatalf("Unexpected result after %d calls: %+v", detector.calls, result)
+		}
+		french := result.Findings[0].Details.(LanguageRange)
+		if french.FirstIndex != 1 || french.LastIndex != 6 || french.EndTime != 29 {
+			t.Errorf("Unexpected French range: %+v", french)
+		}
+		if n := strings.Count(french.Text, "Bonjour encore"); n != 1 {
+			t.Errorf("Expected the text of each cue once, found cue 3 %d times in %q", n, french.Text)
+		}
+		if german := result.Findings[1].Details.(LanguageRange); german.FirstIndex != 6 || german.LastIndex != 7 {
+			t.Errorf("Unexpected German range: %+v", german)
+		}
+	})
+
+	t.Run("Window boundary", func(t *testing.T) {
+		// French from 12 to 28 seconds, across the boundary at 20 seconds.
+		// Neither [0,20) nor [20,40) is mostly French, but [10,30) is.
+		var crossing []parser.Caption
+		for i, start := range []float64{0, 4, 8, 12, 16, 20, 24, 28, 32, 36} {
+			text := "Hello there."
+			if start >= 12 && start < 28 {
+				text = "Bonjour."
+			}
+			crossing = append(crossing, parser.Caption{Index: i + 1, StartTime: start, EndTime: start + 3, Text: text})
+		}
+		calls := 0
+		majority := func(text string, expected string) (client.LanguageValidationResult, error) {
+			calls++
+			language := "en"
+			if strings.Count(text, "Bonjour") > strings.Count(text, "Hello") {
+				language = "fr"
+			}
+			return client.LanguageValidationResult{Language: language, ExpectedLang: expected, Confidence: 0.9}, nil
+		}
+
+		result, err := ValidateLanguageSegments(crossing, LanguageSegmentOptions{Expected: "en", Window: 20}, majority)
+		if err != nil {
+			t.Fatalf("ValidateLanguageSegments() error = %v", err)
+		}
+		if calls != 3 || len(result.Findings) != 1 {
+			t.Fatalf("Unexpected result after %d calls: %+v", calls, result)
+		}
+		if r := result.Findings[0].Details.(LanguageRange); r.Detected != "fr" || r.StartTime != 12 || r.EndTime != 31 {
+			t.Errorf("Unexpected range: %+v", r)
+		}
+	})
+
+	t.Run("Low confidence", func(t *testing.T) {
+		unsure := func(text string, expected string) (client.LanguageValidationResult, error) {
+			return client.LanguageValidationResult{Language: "fr", ExpectedLang: expected, Confidence: 0.3}, nil
+		}
+		result, err := ValidateLanguageSegments(captions, LanguageSegmentOptions{Expected: "en", MinConfidence: 0.5}, unsure)
+		if err != nil || !result.Valid {
+			t.Errorf("Expected unsure detections to be ignored, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("Undetermined", func(t *testing.T) {
+		unsure := func(text string, expected string) (client.LanguageValidationResult, error) {
+			return client.LanguageValidationResult{Language: "und", ExpectedLang: expected}, nil
+		}
+		result, err := ValidateLanguageSegments(captions, LanguageSegmentOptions{Expected: "en"}, unsure)
+		if err != nil || !result.Valid {
+			t.Errorf("Expected undetermined segments to be ignored, got %+v, %v", result, err)
+		}
+
+		rule, _ := NewRule("language", nil)
+		if _, err := rule.Check(Context{Captions: captions, ValidateLanguage: unsure}); !errors.Is(err, ErrSkipped) {
+			t.Errorf("Expected an undetermined file to be skipped, got %v", err)
+		}
+	})
+
+	t.Run("Detector failure", func(t *testing.T) {
+		failing := func(text string, expected string) (client.LanguageValidationResult, error) {
+			return client.LanguageValidationResult{}, errors.New("connection refused")
+		}
+		if _, err := ValidateLanguageSegments(captions, LanguageSegmentOptions{Expected: "en"}, failing); err == nil {
+			t.Error("Expected the detector's error")
+		}
+	})
+}
+
+func TestLanguageRuleModes(t *testing.T) {
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 0, EndTime: 4, Text: "Where are you going tonight, my friend?"},
+		{Index: 2, StartTime: 5, EndTime: 9, Text: "<c.foreign>Bonjour, je vais au marché.</c>"},
+		{Index: 3, StartTime: 10, EndTime: 14, Text: "Hallo, wie geht es dir heute?"},
+	}
+
+	tests := []struct {
+		params   Params
+		findings int
+		calls    int
+	}{
+		// One check of the whole file, marked French included
+		{Params{"expected": "en", "match": "prefix"}, 1, 1},
+		{Params{"expected": "en", "match": "prefix", "mode": "cue"}, 1, 2},
+		{Params{"expected": "en", "match": "prefix", "mode": "cue", "allow": []interface{}{}}, 2, 3},
+		{Params{"expected": "en", "match": "prefix", "mode": "cue", "allow_languages": "de, fr"}, 0, 2},
+		{Params{"expected": "en", "match": "prefix", "mode": "window", "window": 60}, 1, 1},
+	}
+	for _, tt := range tests {
+		rule, err := NewRule("language", tt.params)
+		if err != nil {
+			t.Fatalf("NewRule(%v) error = %v", tt.params, err)
+		}
+		detector := &wordDetector{}
+		result, err := rule.Check(Context{Captions: captions, ValidateLanguage: detector.validate})
+		if err != nil || len(result.Findings) != tt.findings || detector.calls != tt.calls {
+			t.Errorf("%v: expected %d findings from %d calls, got %d from %d (%v)",
+				tt.params, tt.findings, tt.calls, len(result.Findings), detector.calls, err)
+		}
+	}
+
+	// File mode checks all the text unless allow is given
+	for _, tt := range []struct {
+		params  Params
+		checked string
+	}{
+		{nil, "Where are you going tonight, my friend? Bonjour, je vais au marché. Hallo, wie geht es dir heute?"},
+		{Params{"allow": "foreign"}, "Where are you going tonight, my friend? Hallo, wie geht es dir heute?"},
+	} {
+		rule, err := NewRule("language", tt.params)
+		if err != nil {
+			t.Fatalf("NewRule(%v) error = %v", tt.params, err)
+		}
+		var checked string
+		record := func(text string, expected string) (client.LanguageValidationResult, error) {
+			checked = text
+			return client.LanguageValidationResult{Language: "en", ExpectedLang: expected}, nil
+		}
+		if _, err := rule.Check(Context{Captions: captions, ValidateLanguage: record}); err != nil || checked != tt.checked {
+			t.Errorf("%v: expected %q to be checked, got %q (%v)", tt.params, tt.checked, checked, err)
+		}
+	}
+
+	for _, params := range []Params{
+		{"mode": "scene"},
+		{"mode": "window", "window": 0},
+		{"mode": "cue", "window": 10},
+		{"allow": "bold"},
+		{"allow": 3},
+		{"mode": "cue", "allow_languages": "French"},
+		{"mode": "cue", "min_confidence": 2},
+		// Only cue and window modes check pieces of the file
+		{"min_chars": 10},
+		{"min_confidence": 0.8},
+		{"mode": "file", "allow_languages": "fr"},
+	} {
+		if _, err := NewRule("language", params); err == nil {
+			t.Errorf("Expected an error for %v", params)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package validator
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"caption-validator/internal/parser"
+)
+
+// CEA-608 captions have 32 columns and pop-on captions at most 4 rows, the
+// line limits of SCC files unless a profile sets its own
+const (
+	CEA608MaxChars = 32
+	CEA608MaxLines = 4
+)
+
+// FormatLineLimits returns the line length and line count the caption format
+// itself imposes, 0 meaning no limit
+func FormatLineLimits(format string) (maxChars int, maxLines int) {
+	if format == parser.FormatSCC {
+		return CEA608MaxChars, CEA608MaxLines
+	}
+	return 0, 0
+}
+
+// LineFinding describes a caption that breaks a line length or line count limit
+type LineFinding struct {
+	Index     int     `json:"index"`
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+	Issue     string  `json:"issue"`          // "line_too_long" or "too_many_lines"
+	Line      int     `json:"line,omitempty"` // 1-based line number for line_too_long
+	Text      string  `json:"text"`           // the offending line, or the whole caption
+	Length    int     `json:"length,omitempty"`
+	Lines     int     `json:"lines,omitempty"`
+}
+
+// overridePattern matches SRT override blocks such as {\an8} or {\i1},
+// which players apply rather than display
+var overridePattern = regexp.MustCompile(`\{\\[^}]*\}`)
+
+// displayText returns the text of a caption as viewers see it: without
+// markup tags or override blocks, and with character references such as
+// &amp; decoded, so a cue measures the same in every caption format
+func displayText(caption parser.Caption) string {
+	return html.UnescapeString(overridePattern.ReplaceAllString(caption.PlainText(), ""))
+}
+
+// captionLines returns the displayed lines of a caption, see displayText,
+// with trailing blank lines dropped
+func captionLines(caption parser.Caption) []string {
+	text := strings.TrimRight(displayText(caption), "\r\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
+
+// ValidateLineLength checks every caption against a maximum number of
+// characters per line and a maximum number of lines. Line length is measured
+// in grapheme clusters, so accented and CJK text counts as it is displayed.
+// A limit of 0 disables that check.
+func ValidateLineLength(captions []parser.Caption, maxChars int, maxLines int) (ValidationResult, error) {
+	if maxChars < 0 || maxLines < 0 {
+		return ValidationResult{}, fmt.Errorf("line limits must not be negative")
+	}
+	if maxChars == 0 && maxLines == 0 {
+		return ValidationResult{}, fmt.Errorf("at least one of max line length and max lines must be set")
+	}
+
+	findings := []LineFinding{}
+	var located []Finding
+	for _, caption := range captions {
+		lines := captionLines(caption)
+
+		if maxLines > 0 && len(lines) > maxLines {
+			finding := LineFinding{
+				Index:     caption.Index,
+				StartTime: caption.StartTime,
+				EndTime:   caption.EndTime,
+				Issue:     "too_many_lines",
+				Text:      strings.Join(lines, "\n"),
+				Lines:     len(lines),
+			}
+			findings = append(findings, finding)
+			located = append(located, Finding{
+				Message:  fmt.Sprintf("Caption has %d lines, more than the limit of %d", finding.Lines, maxLines),
+				Location: cueLocation(caption),
+				Details:  finding,
+			})
+		}
+
+		if maxChars > 0 {
+			for i, line := range lines {
+				if length := graphemeCount(line); length > maxChars {
+					finding := LineFinding{
+						Index:     caption.Index,
+						StartTime: caption.StartTime,
+						EndTime:   caption.EndTime,
+						Issue:     "line_too_long",
+						Line:      i + 1,
+						Text:      line,
+						Length:    length,
+					}
+					location := cueLocation(caption)
+					location.Line = finding.Line
+					findings = append(findings, finding)
+					located = append(located, Finding{
+						Message:  fmt.Sprintf("Line %d is %d characters long, more than the limit of %d", finding.Line, length, maxChars),
+						Location: location,
+						Details:  finding,
+					})
+				}
+			}
+		}
+	}
+
+	result := ValidationResult{
+		Valid:    len(findings) == 0,
+		Findings: located,
+		Type:     "line_length",
+		Data: map[string]interface{}{
+			"captions": findings,
+		},
+	}
+
+	if maxChars > 0 {
+		result.Data["max_chars_per_line"] = maxChars
+	}
+	if maxLines > 0 {
+		result.Data["max_lines"] = maxLines
+	}
+
+	return result, nil
+}
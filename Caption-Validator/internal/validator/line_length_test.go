@@ -0,0 +1,167 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"caption-validator/internal/parser"
+)
+
+func TestGraphemeCount(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"Hello", 5},
+		{"Café", 4},
+		{"Cafe\u0301", 4},         // e + combining acute accent
+		{"日本語の字幕", 6},             // CJK
+		{"한국어", 3},                // precomposed Hangul
+		{"\u1100\u1161\u11a8", 1}, // Hangul jamo forming one syllable
+		{"👍🏽", 1},                 // emoji with skin tone modifier
+		{"👨\u200d👩\u200d👧", 1},    // zero width joiner family
+		{"🇫🇷🇩🇪", 2},               // two flags
+		{"❤\ufe0f", 1},            // heart with emoji presentation selector
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := graphemeCount(tt.text); got != tt.want {
+			t.Errorf("graphemeCount(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestValidateLineLength(t *testing.T) {
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 0, EndTime: 2, Text: "Short line\nAnother short line"},
+		{Index: 2, StartTime: 2, EndTime: 4, Text: "<i>This line is far longer than the forty-two character limit</i>"},
+		{Index: 3, StartTime: 4, EndTime: 6, Text: "One\nTwo\nThree"},
+		// 42 accented characters, 84 bytes in UTF-8
+		{Index: 4, StartTime: 6, EndTime: 8, Text: "éééééééééééééééééééééééééééééééééééééééééé"},
+	}
+
+	result, err := ValidateLineLength(captions, 42, 2)
+	if err != nil {
+		t.Fatalf("ValidateLineLength() error = %v", err)
+	}
+	if result.Valid || result.Type != "line_length" {
+		t.Errorf("Expected line_length failure, got %+v", result)
+	}
+
+	var jsonObj struct {
+		MaxChars int           `json:"max_chars_per_line"`
+		MaxLines int           `json:"max_lines"`
+		Captions []LineFinding `json:"captions"`
+	}
+	if err := json.Unmarshal([]byte(result.JSON()), &jsonObj); err != nil {
+		t.Fatalf("Failed to parse JSON result: %v", err)
+	}
+	if jsonObj.MaxChars != 42 || jsonObj.MaxLines != 2 || len(jsonObj.Captions) != 2 {
+		t.Fatalf("Unexpected line length report: %+v", jsonObj)
+	}
+
+	long := jsonObj.Captions[0]
+	if long.Index != 2 || long.Issue != "line_too_long" || long.Line != 1 || long.Length != 58 ||
+		long.Text != "This line is far longer than the forty-two character limit" {
+		t.Errorf("Unexpected line_too_long finding: %+v", long)
+	}
+	many := jsonObj.Captions[1]
+	if many.Index != 3 || many.Issue != "too_many_lines" || many.Lines != 3 {
+		t.Errorf("Unexpected too_many_lines finding: %+v", many)
+	}
+
+	if len(result.Findings) != 2 {
+		t.Fatalf("Expected one finding per problem, got %+v", result.Findings)
+	}
+	if loc := result.Findings[0].Location; loc == nil || loc.CueIndex != 2 || loc.Line != 1 || loc.StartTime != 2 || loc.EndTime != 4 {
+		t.Errorf("Expected line_too_long finding located at cue 2 line 1, got %+v", loc)
+	}
+	if loc := result.Findings[1].Location; loc == nil || loc.CueIndex != 3 || loc.Line != 0 {
+		t.Errorf("Expected too_many_lines finding located at cue 3, got %+v", loc)
+	}
+
+	// 608-origin limits: 32 characters, 4 rows
+	result, err = ValidateLineLength(captions, 32, 4)
+	if err != nil {
+		t.Fatalf("ValidateLineLength() error = %v", err)
+	}
+	if findings := result.Data["captions"].([]LineFinding); len(findings) != 2 {
+		t.Errorf("Expected 2 findings with 32x4 limits, got %+v", findings)
+	}
+
+	if _, err := ValidateLineLength(captions, 0, 0); err == nil {
+		t.Error("Expected error when no limit is set")
+	}
+}
+
+func TestValidateLineLengthDisplayText(t *testing.T) {
+	// The same cue as written to SRT and to WebVTT by convert: 36 characters
+	// on screen in both
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 0, EndTime: 2, Text: "{\\an8}Tom & Jerry & Spike & Tyke & Butch!!"},
+		{Index: 2, StartTime: 0, EndTime: 2, Text: "Tom &amp; Jerry &amp; Spike &amp; Tyke &amp; Butch!!"},
+		{Index: 3, StartTime: 0, EndTime: 2, Text: "{\\i1}Tom &lt;3{\\i0} &#74;erry"},
+	}
+
+	result, err := ValidateLineLength(captions[:2], 36, 0)
+	if err != nil || !result.Valid {
+		t.Errorf("Expected 36 characters to fit, got %+v, %v", result, err)
+	}
+	result, err = ValidateLineLength(captions, 10, 0)
+	if err != nil || len(result.Findings) != 3 {
+		t.Fatalf("Expected every cue to be too long, got %+v, %v", result, err)
+	}
+	for i, want := range []struct {
+		text   string
+		length int
+	}{
+		{"Tom & Jerry & Spike & Tyke & Butch!!", 36},
+		{"Tom & Jerry & Spike & Tyke & Butch!!", 36},
+		{"Tom <3 Jerry", 12},
+	} {
+		if got := result.Findings[i].Details.(LineFinding); got.Text != want.text || got.Length != want.length {
+			t.Errorf("Cue %d: got %q of length %d, want %q of length %d", i+1, got.Text, got.Length, want.text, want.length)
+		}
+	}
+}
+
+func TestLineLengthRuleFormatDefaults(t *testing.T) {
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 0, EndTime: 2, Text: "This line is thirty-six chars long"},
+		{Index: 2, StartTime: 2, EndTime: 4, Text: "One\nTwo\nThree"},
+		{Index: 3, StartTime: 4, EndTime: 6, Text: "One\nTwo\nThree\nFour\nFive"},
+	}
+
+	tests := []struct {
+		params   Params
+		format   string
+		findings int
+	}{
+		// SCC is held to the CEA-608 32 columns and 4 rows
+		{nil, parser.FormatSCC, 2},
+		{Params{"max_chars_per_line": 40}, parser.FormatSCC, 1},
+		{Params{"max_lines": 2}, parser.FormatSCC, 3},
+		{Params{"max_lines": 2}, parser.FormatWebVTT, 2},
+	}
+	for _, tt := range tests {
+		rule, err := NewRule("line_length", tt.params)
+		if err != nil {
+			t.Fatalf("NewRule(%v) error = %v", tt.params, err)
+		}
+		result, err := rule.Check(Context{Captions: captions, Format: tt.format})
+		if err != nil || len(result.Findings) != tt.findings {
+			t.Errorf("%v on %s: expected %d findings, got %+v, %v", tt.params, tt.format, tt.findings, result.Findings, err)
+		}
+	}
+
+	// Other formats have no limits of their own
+	rule, _ := NewRule("line_length", nil)
+	if _, err := rule.Check(Context{Captions: captions, Format: parser.FormatSRT}); !errors.Is(err, ErrSkipped) {
+		t.Errorf("Expected the rule to be skipped for SRT without limits, got %v", err)
+	}
+	if _, err := NewRule("line_length", Params{"max_lines": -1}); err == nil {
+		t.Error("Expected an error for a negative limit")
+	}
+}
@@ -0,0 +1,173 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"caption-validator/internal/parser"
+)
+
+// OrderingFinding describes a cue that overlaps another, starts before the cue
+// preceding it in the file, or carries an unexpected SRT index
+type OrderingFinding struct {
+	Index     int     `json:"index"`
+	Position  int     `json:"position"` // 1-based position of the cue in the file
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+	Issue     string  `json:"issue"` // "overlap", "out_of_order", "index_skipped" or "index_repeated"
+	// The other cue of an overlapping pair, or the cue before an out of order one
+	PreviousIndex    int     `json:"previous_index,omitempty"`
+	PreviousPosition int     `json:"previous_position,omitempty"`
+	Overlap          float64 `json:"overlap,omitempty"` // seconds both cues are on screen
+	ExpectedIndex    int     `json:"expected_index,omitempty"`
+}
+
+// ValidateOrdering reports every pair of overlapping cues and every cue that
+// starts earlier than the cue before it. When checkIndices is set (for SRT,
+// whose parser keeps the numbers written in the file) it also reports cue
+// numbers that skip or repeat.
+func ValidateOrdering(captions []parser.Caption, checkIndices bool) ValidationResult {
+	findings := []OrderingFinding{}
+
+	for i := 1; i < len(captions); i++ {
+		previous, caption := captions[i-1], captions[i]
+		if caption.StartTime < previous.StartTime {
+			findings = append(findings, OrderingFinding{
+				Index:            caption.Index,
+				Position:         i + 1,
+				StartTime:        caption.StartTime,
+				EndTime:          caption.EndTime,
+				Issue:            "out_of_order",
+				PreviousIndex:    previous.Index,
+				PreviousPosition: i,
+			})
+		}
+	}
+
+	findings = append(findings, findOverlaps(captions)...)
+
+	if checkIndices {
+		findings = append(findings, findIndexErrors(captions)...)
+	}
+
+	result := ValidationResult{
+		Valid:    len(findings) == 0,
+		Findings: make([]Finding, 0, len(findings)),
+		Type:     "cue_ordering",
+		Data: map[string]interface{}{
+			"captions": findings,
+		},
+	}
+
+	for _, finding := range findings {
+		var message string
+		switch finding.Issue {
+		case "out_of_order":
+			message = fmt.Sprintf("Caption starts before caption %d, which comes before it in the file", finding.PreviousIndex)
+		case "overlap":
+			message = fmt.Sprintf("Caption overlaps caption %d by %.3f seconds", finding.PreviousIndex, finding.Overlap)
+		case "index_skipped":
+			message = fmt.Sprintf("Caption is numbered %d, expected %d", finding.Index, finding.ExpectedIndex)
+		case "index_repeated":
+			message = fmt.Sprintf("Caption number %d is used more than once", finding.Index)
+		}
+		result.Findings = append(result.Findings, Finding{
+			Message:  message,
+			Location: cueLocation(captions[finding.Position-1]),
+			Details:  finding,
+		})
+	}
+
+	return result
+}
+
+// findOverlaps sweeps the cues in start time order and reports each pair that
+// is on screen at the same time, including pairs that are not adjacent
+func findOverlaps(captions []parser.Caption) []OrderingFinding {
+	order := make([]int, len(captions))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return captions[order[a]].StartTime < captions[order[b]].StartTime
+	})
+
+	var findings []OrderingFinding
+	var active []int // cues that have started and may still be on screen
+
+	for _, i := range order {
+		caption := captions[i]
+		if caption.EndTime <= caption.StartTime {
+			continue
+		}
+
+		stillActive := active[:0]
+		for _, j := range active {
+			other := captions[j]
+			if other.EndTime <= caption.StartTime {
+				continue
+			}
+			stillActive = append(stillActive, j)
+
+			// Report against the cue that comes first in the file
+			first, second := j, i
+			if second < first {
+				first, second = second, first
+			}
+			findings = append(findings, OrderingFinding{
+				Index:            captions[second].Index,
+				Position:         second + 1,
+				StartTime:        captions[second].StartTime,
+				EndTime:          captions[second].EndTime,
+				Issue:            "overlap",
+				PreviousIndex:    captions[first].Index,
+				PreviousPosition: first + 1,
+				Overlap:          roundMillis(math.Min(caption.EndTime, other.EndTime) - caption.StartTime),
+			})
+		}
+		active = append(stillActive, i)
+	}
+
+	// Keep the report in file order
+	sort.SliceStable(findings, func(a, b int) bool {
+		if findings[a].Position != findings[b].Position {
+			return findings[a].Position < findings[b].Position
+		}
+		return findings[a].PreviousPosition < findings[b].PreviousPosition
+	})
+
+	return findings
+}
+
+// findIndexErrors reports cue numbers that do not follow 1, 2, 3, ...
+func findIndexErrors(captions []parser.Caption) []OrderingFinding {
+	var findings []OrderingFinding
+	seen := make(map[int]bool)
+	expected := 1
+
+	for i, caption := range captions {
+		finding := OrderingFinding{
+			Index:         caption.Index,
+			Position:      i + 1,
+			StartTime:     caption.StartTime,
+			EndTime:       caption.EndTime,
+			ExpectedIndex: expected,
+		}
+
+		switch {
+		case seen[caption.Index]:
+			finding.Issue = "index_repeated"
+			findings = append(findings, finding)
+		case caption.Index != expected:
+			finding.Issue = "index_skipped"
+			findings = append(findings, finding)
+		}
+
+		seen[caption.Index] = true
+		// Continue counting from the number in the file so one skip is one finding
+		expected = caption.Index + 1
+	}
+
+	return findings
+}
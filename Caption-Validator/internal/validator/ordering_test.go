@@ -0,0 +1,67 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"caption-validator/internal/parser"
+)
+
+func TestValidateOrdering(t *testing.T) {
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 0.0, EndTime: 10.0, Text: "Long cue"},
+		{Index: 2, StartTime: 2.0, EndTime: 4.0, Text: "Inside the first"},
+		{Index: 3, StartTime: 9.5, EndTime: 12.0, Text: "Overlaps the first"},
+		{Index: 5, StartTime: 13.0, EndTime: 14.0, Text: "Skips 4"},
+		{Index: 5, StartTime: 15.0, EndTime: 16.0, Text: "Repeats 5"},
+		{Index: 6, StartTime: 12.5, EndTime: 12.9, Text: "Goes backwards"},
+	}
+
+	result := ValidateOrdering(captions, true)
+	if result.Valid || result.Type != "cue_ordering" {
+		t.Errorf("Expected cue_ordering failure, got %+v", result)
+	}
+
+	var jsonObj struct {
+		Captions []OrderingFinding `json:"captions"`
+	}
+	if err := json.Unmarshal([]byte(result.JSON()), &jsonObj); err != nil {
+		t.Fatalf("Failed to parse JSON result: %v", err)
+	}
+
+	want := []struct {
+		issue    string
+		position int
+		previous int
+	}{
+		{"out_of_order", 6, 5},
+		{"overlap", 2, 1},
+		{"overlap", 3, 1},
+		{"index_skipped", 4, 0},
+		{"index_repeated", 5, 0},
+	}
+	if len(jsonObj.Captions) != len(want) {
+		t.Fatalf("Expected %d findings, got %+v", len(want), jsonObj.Captions)
+	}
+	for i, w := range want {
+		got := jsonObj.Captions[i]
+		if got.Issue != w.issue || got.Position != w.position || got.PreviousPosition != w.previous {
+			t.Errorf("Finding %d = %+v, want %s at %d (previous %d)", i, got, w.issue, w.position, w.previous)
+		}
+	}
+	if overlap := jsonObj.Captions[2]; overlap.Overlap != 0.5 || overlap.PreviousIndex != 1 {
+		t.Errorf("Unexpected overlap finding: %+v", overlap)
+	}
+	if skipped := jsonObj.Captions[3]; skipped.Index != 5 || skipped.ExpectedIndex != 4 {
+		t.Errorf("Unexpected index finding: %+v", skipped)
+	}
+
+	// Without index checks, sequential cues that only touch are valid
+	touching := []parser.Caption{
+		{Index: 7, StartTime: 0, EndTime: 1},
+		{Index: 9, StartTime: 1, EndTime: 2},
+	}
+	if result := ValidateOrdering(touching, false); !result.Valid {
+		t.Errorf("Expected touching cues to pass, got %+v", result.Data)
+	}
+}
@@ -0,0 +1,150 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile is a house style: the rules to run, their parameters and how
+// severe each failure is. Profiles are read from YAML or JSON files.
+//
+//	name: house-style
+//	rules:
+//	  - rule: coverage
+//	    params: {min_coverage: 95}
+//	  - rule: reading_speed
+//	    severity: warning
+//	    params: {max_cps: 17}
+type Profile struct {
+	Name        string       `yaml:"name" json:"name,omitempty"`
+	Description string       `yaml:"description" json:"description,omitempty"`
+	Rules       []RuleConfig `yaml:"rules" json:"rules"`
+}
+
+// RuleConfig enables one rule in a profile. The same rule may appear more
+// than once, e.g. a reading speed warning at 17 CPS and an error at 20 CPS.
+type RuleConfig struct {
+	Rule     string `yaml:"rule" json:"rule"`
+	Severity string `yaml:"severity" json:"severity,omitempty"` // error (default), warning or info
+	Enabled  *bool  `yaml:"enabled" json:"enabled,omitempty"`   // defaults to true
+	Params   Params `yaml:"params" json:"params,omitempty"`
+}
+
+// LoadProfile reads a profile from a YAML or JSON file
+func LoadProfile(path string) (Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	profile, err := ParseProfile(data)
+	if err != nil {
+		return Profile{}, fmt.Errorf("invalid profile %s: %w", path, err)
+	}
+	return profile, nil
+}
+
+// ParseProfile parses a YAML or JSON profile (JSON is valid YAML) and checks
+// that every rule, parameter and severity in it is known
+func ParseProfile(data []byte) (Profile, error) {
+	var profile Profile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return Profile{}, err
+	}
+	if len(profile.Rules) == 0 {
+		return Profile{}, errors.New("profile enables no rules")
+	}
+	if _, err := NewEngine(profile); err != nil {
+		return Profile{}, err
+	}
+	return profile, nil
+}
+
+// WithParam returns a copy of the profile with a parameter set on every
+// entry for rule. If the profile has no such rule, one is added with error
+// severity. Batch manifests use this to override settings per file.
+func (p Profile) WithParam(rule string, name string, value interface{}) Profile {
+	copied := p
+	copied.Rules = make([]RuleConfig, 0, len(p.Rules)+1)
+
+	found := false
+	for _, config := range p.Rules {
+		if config.Rule == rule {
+			params := make(Params, len(config.Params)+1)
+			for k, v := range config.Params {
+				params[k] = v
+			}
+			params[name] = value
+			config.Params = params
+			found = true
+		}
+		copied.Rules = append(copied.Rules, config)
+	}
+	if !found {
+		copied.Rules = append(copied.Rules, RuleConfig{Rule: rule, Params: Params{name: value}})
+	}
+
+	return copied
+}
+
+// Outcome is the result of running one configured rule on a file
+type Outcome struct {
+	Rule     string
+	Severity Severity
+	Result   ValidationResult
+	Duration time.Duration // time spent in the rule's check
+	// Err is set when the rule could not run; errors.Is(Err, ErrSkipped)
+	// distinguishes a skipped rule from unusable input
+	Err error
+}
+
+// Engine runs the rules of a profile
+type Engine struct {
+	rules      []Rule
+	severities []Severity
+}
+
+// NewEngine builds the enabled rules of a profile
+func NewEngine(profile Profile) (*Engine, error) {
+	engine := &Engine{}
+	for i, config := range profile.Rules {
+		if config.Enabled != nil && !*config.Enabled {
+			continue
+		}
+		if config.Rule == "" {
+			return nil, fmt.Errorf("rule %d has no name", i+1)
+		}
+
+		severity, err := ParseSeverity(config.Severity)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", config.Rule, err)
+		}
+		rule, err := NewRule(config.Rule, config.Params)
+		if err != nil {
+			return nil, err
+		}
+
+		engine.rules = append(engine.rules, rule)
+		engine.severities = append(engine.severities, severity)
+	}
+	return engine, nil
+}
+
+// Run checks one file against every rule, in profile order. Failed results
+// carry the severity configured for their rule.
+func (e *Engine) Run(ctx Context) []Outcome {
+	outcomes := make([]Outcome, 0, len(e.rules))
+	for i, rule := range e.rules {
+		outcome := Outcome{Rule: rule.Name(), Severity: e.severities[i]}
+		started := time.Now()
+		outcome.Result, outcome.Err = rule.Check(ctx)
+		outcome.Duration = time.Since(started)
+		outcome.Result.Severity = outcome.Severity
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes
+}
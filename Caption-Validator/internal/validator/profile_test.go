@@ -0,0 +1,219 @@
+package validator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"caption-validator/internal/client"
+	"caption-validator/internal/parser"
+)
+
+func TestParseProfile(t *testing.T) {
+	yamlProfile := `
+name: house-style
+rules:
+  - rule: coverage
+    params:
+      min_coverage: 90
+  - rule: reading_speed
+    severity: warning
+    params: {max_cps: 17}
+  - rule: reading_speed
+    params: {max_cps: 25}
+  - rule: line_length
+    enabled: false
+    params: {max_chars_per_line: 42}
+  - rule: language
+    severity: info
+`
+	jsonProfile := `{"name": "house-style", "rules": [
+		{"rule": "coverage", "params": {"min_coverage": 90}},
+		{"rule": "reading_speed", "severity": "warning", "params": {"max_cps": 17}},
+		{"rule": "reading_speed", "params": {"max_cps": 25}},
+		{"rule": "line_length", "enabled": false, "params": {"max_chars_per_line": 42}},
+		{"rule": "language", "severity": "info"}
+	]}`
+
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 0, EndTime: 1, Text: "Twenty characters!!!"},
+		{Index: 2, StartTime: 1, EndTime: 10, Text: "Slow enough to read comfortably"},
+	}
+
+	for name, data := range map[string]string{"yaml": yamlProfile, "json": jsonProfile} {
+		t.Run(name, func(t *testing.T) {
+			profile, err := ParseProfile([]byte(data))
+			if err != nil {
+				t.Fatalf("ParseProfile() error = %v", err)
+			}
+			if profile.Name != "house-style" || len(profile.Rules) != 5 {
+				t.Fatalf("Unexpected profile: %+v", profile)
+			}
+
+			engine, err := NewEngine(profile)
+			if err != nil {
+				t.Fatalf("NewEngine() error = %v", err)
+			}
+			outcomes := engine.Run(Context{
+				Captions:  captions,
+				StartTime: 0,
+				EndTime:   10,
+				ValidateLanguage: func(text string, expected string) (client.LanguageValidationResult, error) {
+					return client.LanguageValidationResult{Valid: false, Language: "es-ES", ExpectedLang: expected}, nil
+				},
+			})
+
+			// The disabled line_length rule does not run
+			want := []struct {
+				rule     string
+				severity Severity
+				valid    bool
+			}{
+				{"coverage", SeverityError, true},
+				{"reading_speed", SeverityWarning, false},
+				{"reading_speed", SeverityError, true},
+				{"language", SeverityInfo, false},
+			}
+			if len(outcomes) != len(want) {
+				t.Fatalf("Expected %d outcomes, got %+v", len(want), outcomes)
+			}
+			for i, w := range want {
+				got := outcomes[i]
+				if got.Err != nil || got.Rule != w.rule || got.Severity != w.severity || got.Result.Valid != w.valid {
+					t.Errorf("Outcome %d = %+v, want %s/%s valid=%v", i, got, w.rule, w.severity, w.valid)
+				}
+			}
+			if !strings.Contains(outcomes[1].Result.JSON(), `"severity":"warning"`) {
+				t.Errorf("Expected severity in JSON output, got %s", outcomes[1].Result.JSON())
+			}
+			if outcomes[3].Result.Type != "incorrect_language" || outcomes[3].Result.Data["expected"] != "en-US" {
+				t.Errorf("Unexpected language result: %+v", outcomes[3].Result)
+			}
+		})
+	}
+}
+
+func TestParseProfileErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown rule":      "rules: [{rule: spelling}]",
+		"unknown parameter": "rules: [{rule: coverage, params: {min_coverag: 90}}]",
+		"bad severity":      "rules: [{rule: coverage, severity: fatal}]",
+		"bad parameter":     "rules: [{rule: coverage, params: {min_coverage: lots}}]",
+		"missing parameter": "rules: [{rule: max_gap}]",
+		"no rules":          "name: empty",
+		"not a profile":     "- just\n- a list",
+	}
+	for name, data := range tests {
+		if _, err := ParseProfile([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLanguageRuleSkipped(t *testing.T) {
+	rule, err := NewRule("language", Params{"expected": "fr-FR"})
+	if err != nil {
+		t.Fatalf("NewRule() error = %v", err)
+	}
+
+	if _, err := rule.Check(Context{}); !errors.Is(err, ErrSkipped) {
+		t.Errorf("Expected ErrSkipped without a language API, got %v", err)
+	}
+
+	apiDown := func(string, string) (client.LanguageValidationResult, error) {
+		return client.LanguageValidationResult{}, errors.New("connection refused")
+	}
+	if _, err := rule.Check(Context{ValidateLanguage: apiDown}); !errors.Is(err, ErrSkipped) || !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Expected ErrSkipped and ErrUnavailable when the API fails, got %v", err)
+	}
+	if _, err := rule.Check(Context{}); errors.Is(err, ErrUnavailable) {
+		t.Errorf("A language API that is not configured is not unavailable, got %v", err)
+	}
+	// Strict mode fails the rule instead, but still skips it without an API
+	if _, err := rule.Check(Context{ValidateLanguage: apiDown, Strict: true}); errors.Is(err, ErrSkipped) || !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Expected only ErrUnavailable in strict mode, got %v", err)
+	}
+	if _, err := rule.Check(Context{Strict: true}); !errors.Is(err, ErrSkipped) {
+		t.Errorf("Expected ErrSkipped without a language API in strict mode, got %v", err)
+	}
+
+	// A per-file language overrides the profile parameter
+	var asked string
+	check := func(text string, expected string) (client.LanguageValidationResult, error) {
+		asked = expected
+		return client.LanguageValidationResult{Valid: true, Language: expected, ExpectedLang: expected}, nil
+	}
+	if _, err := rule.Check(Context{ValidateLanguage: check}); err != nil || asked != "fr-FR" {
+		t.Errorf("Expected fr-FR from params, got %q (%v)", asked, err)
+	}
+	if _, err := rule.Check(Context{ValidateLanguage: check, ExpectedLanguage: "de-DE"}); err != nil || asked != "de-DE" {
+		t.Errorf("Expected de-DE from context, got %q (%v)", asked, err)
+	}
+}
+
+func TestLanguageRuleMatch(t *testing.T) {
+	detected := func(lang string) func(string, string) (client.LanguageValidationResult, error) {
+		return func(text string, expected string) (client.LanguageValidationResult, error) {
+			return client.ValidateLanguageWith(fixedDetector(lang), text, expected)
+		}
+	}
+
+	tests := []struct {
+		params   Params
+		ctx      Context
+		detected string
+		valid    bool
+	}{
+		{Params{"expected": "en-US"}, Context{}, "en-US", true},
+		{Params{"expected": "en-US"}, Context{}, "en-GB", false},
+		{Params{"expected": "en_us"}, Context{}, "EN-us", true},
+		{Params{"expected": "en-US", "match": "language"}, Context{}, "en-GB", true},
+		{Params{"expected": "en-US", "match": "prefix"}, Context{}, "en", true},
+		{Params{"expected": "en-US", "match": "prefix"}, Context{}, "en-GB", false},
+		{Params{"expected": "en"}, Context{LanguageMatch: client.MatchPrefix}, "en-GB", true},
+		{Params{"expected": "en-US", "match": "language"}, Context{ExpectedLanguage: "fr"}, "en-US", false},
+	}
+	for _, tt := range tests {
+		rule, err := NewRule("language", tt.params)
+		if err != nil {
+			t.Fatalf("NewRule(%v) error = %v", tt.params, err)
+		}
+		ctx := tt.ctx
+		ctx.ValidateLanguage = detected(tt.detected)
+		result, err := rule.Check(ctx)
+		if err != nil || result.Valid != tt.valid {
+			t.Errorf("%v %+v with %s detected: valid = %v (%v), want %v", tt.params, tt.ctx, tt.detected, result.Valid, err, tt.valid)
+		}
+	}
+
+	for _, params := range []Params{{"expected": "English"}, {"expected": "en-US", "match": "fuzzy"}} {
+		if _, err := NewRule("language", params); err == nil {
+			t.Errorf("NewRule(%v): expected error", params)
+		}
+	}
+}
+
+// fixedDetector reports the same language for any text
+type fixedDetector string
+
+func (d fixedDetector) DetectLanguage(text string) (client.LanguageDetection, error) {
+	return client.LanguageDetection{Language: string(d)}, nil
+}
+
+func TestProfileWithParam(t *testing.T) {
+	profile := Profile{Rules: []RuleConfig{
+		{Rule: "coverage", Severity: "warning", Params: Params{"min_coverage": 95.0}},
+	}}
+
+	updated := profile.WithParam("coverage", "min_coverage", 80.0).WithParam("max_gap", "max_gap", 10.0)
+
+	if profile.Rules[0].Params["min_coverage"] != 95.0 || len(profile.Rules) != 1 {
+		t.Errorf("WithParam modified the original profile: %+v", profile)
+	}
+	if len(updated.Rules) != 2 || updated.Rules[0].Params["min_coverage"] != 80.0 || updated.Rules[0].Severity != "warning" {
+		t.Errorf("Unexpected coverage rule: %+v", updated.Rules)
+	}
+	if updated.Rules[1].Rule != "max_gap" || updated.Rules[1].Params["max_gap"] != 10.0 {
+		t.Errorf("Expected max_gap rule to be added, got %+v", updated.Rules)
+	}
+}
@@ -0,0 +1,96 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"caption-validator/internal/parser"
+)
+
+// ReadingSpeed is the measured reading speed of a single caption
+type ReadingSpeed struct {
+	Index     int     `json:"index"`
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+	Text      string  `json:"text"`
+	CPS       float64 `json:"cps"`
+	WPM       float64 `json:"wpm"`
+}
+
+// MeasureReadingSpeed computes characters per second and words per minute for
+// a caption. Characters are counted in the displayed text as line length
+// counts them, so markup, override blocks and line breaks count as nothing,
+// since viewers do not read them. ok is false for captions with no duration.
+func MeasureReadingSpeed(caption parser.Caption) (speed ReadingSpeed, ok bool) {
+	duration := caption.EndTime - caption.StartTime
+	if duration <= 0 {
+		return ReadingSpeed{}, false
+	}
+
+	text := displayText(caption)
+	chars := graphemeCount(strings.ReplaceAll(text, "\n", ""))
+	words := len(strings.Fields(text))
+
+	return ReadingSpeed{
+		Index:     caption.Index,
+		StartTime: caption.StartTime,
+		EndTime:   caption.EndTime,
+		Text:      text,
+		CPS:       math.Round(float64(chars)/duration*100) / 100,
+		WPM:       math.Round(float64(words)/duration*60*100) / 100,
+	}, true
+}
+
+// ValidateReadingSpeed reports every caption faster than maxCPS characters per
+// second or maxWPM words per minute. A limit of 0 disables that measure.
+func ValidateReadingSpeed(captions []parser.Caption, maxCPS float64, maxWPM float64) (ValidationResult, error) {
+	if maxCPS < 0 || maxWPM < 0 {
+		return ValidationResult{}, fmt.Errorf("reading speed limits must not be negative")
+	}
+	if maxCPS == 0 && maxWPM == 0 {
+		return ValidationResult{}, fmt.Errorf("at least one of max CPS and max WPM must be set")
+	}
+
+	tooFast := []ReadingSpeed{}
+	var findings []Finding
+	for _, caption := range captions {
+		speed, ok := MeasureReadingSpeed(caption)
+		if !ok {
+			continue
+		}
+
+		var message string
+		switch {
+		case maxCPS > 0 && speed.CPS > maxCPS:
+			message = fmt.Sprintf("Caption reads at %.2f characters per second, above the limit of %g", speed.CPS, maxCPS)
+		case maxWPM > 0 && speed.WPM > maxWPM:
+			message = fmt.Sprintf("Caption reads at %.2f words per minute, above the limit of %g", speed.WPM, maxWPM)
+		default:
+			continue
+		}
+		tooFast = append(tooFast, speed)
+		findings = append(findings, Finding{
+			Message:  message,
+			Location: cueLocation(caption),
+			Details:  speed,
+		})
+	}
+
+	result := ValidationResult{
+		Valid:    len(tooFast) == 0,
+		Findings: findings,
+		Type:     "reading_speed",
+		Data: map[string]interface{}{
+			"captions": tooFast,
+		},
+	}
+	if maxCPS > 0 {
+		result.Data["max_cps"] = maxCPS
+	}
+	if maxWPM > 0 {
+		result.Data["max_wpm"] = maxWPM
+	}
+
+	return result, nil
+}
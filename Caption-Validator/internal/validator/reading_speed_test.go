@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"caption-validator/internal/parser"
+)
+
+func TestMeasureReadingSpeed(t *testing.T) {
+	caption := parser.Caption{Index: 7, StartTime: 1.0, EndTime: 3.0, Text: "<i>Hello</i> there,\nfriend"}
+
+	speed, ok := MeasureReadingSpeed(caption)
+	if !ok {
+		t.Fatal("Expected reading speed to be measured")
+	}
+	// "Hello there,friend" is 18 characters over 2 seconds; 3 words
+	if speed.CPS != 9 {
+		t.Errorf("CPS = %v, want 9", speed.CPS)
+	}
+	if speed.WPM != 90 {
+		t.Errorf("WPM = %v, want 90", speed.WPM)
+	}
+	if speed.Text != "Hello there,\nfriend" || speed.Index != 7 {
+		t.Errorf("Unexpected measurement: %+v", speed)
+	}
+
+	// Characters are counted as line length counts them: override blocks are
+	// dropped, &amp; is one character, and so are "é" written with a
+	// combining accent and an emoji with a skin tone
+	for _, tt := range []struct {
+		text string
+		cps  float64
+	}{
+		{"{\\an8}Tom &amp; Jerry", 11},
+		{"Cafe\u0301 👍🏽", 6},
+	} {
+		speed, _ := MeasureReadingSpeed(parser.Caption{StartTime: 0, EndTime: 1, Text: tt.text})
+		if speed.CPS != tt.cps || float64(graphemeCount(speed.Text)) != tt.cps {
+			t.Errorf("MeasureReadingSpeed(%q) = %v CPS over %q, want %v as its line length", tt.text, speed.CPS, speed.Text, tt.cps)
+		}
+	}
+
+	if _, ok := MeasureReadingSpeed(parser.Caption{StartTime: 2, EndTime: 2, Text: "Zero"}); ok {
+		t.Error("Expected zero-duration caption to be skipped")
+	}
+}
+
+func TestValidateReadingSpeed(t *testing.T) {
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 0.0, EndTime: 4.0, Text: "A comfortable pace."},
+		{Index: 2, StartTime: 4.0, EndTime: 5.0, Text: "This one is far too fast to read."},
+		{Index: 3, StartTime: 5.0, EndTime: 6.0, Text: "Ça va très bien"},
+	}
+
+	result, err := ValidateReadingSpeed(captions, 17, 0)
+	if err != nil {
+		t.Fatalf("ValidateReadingSpeed() error = %v", err)
+	}
+	if result.Valid || result.Type != "reading_speed" {
+		t.Errorf("Expected reading_speed failure, got %+v", result)
+	}
+
+	var jsonObj struct {
+		MaxCPS   float64        `json:"max_cps"`
+		Captions []ReadingSpeed `json:"captions"`
+	}
+	if err := json.Unmarshal([]byte(result.JSON()), &jsonObj); err != nil {
+		t.Fatalf("Failed to parse JSON result: %v", err)
+	}
+	// Accented characters count once each: "Ça va très bien" is 15 characters
+	if jsonObj.MaxCPS != 17 || len(jsonObj.Captions) != 1 || jsonObj.Captions[0].Index != 2 || jsonObj.Captions[0].CPS != 33 {
+		t.Errorf("Unexpected reading speed report: %+v", jsonObj)
+	}
+
+	result, err = ValidateReadingSpeed(captions, 0, 300)
+	if err != nil {
+		t.Fatalf("ValidateReadingSpeed() error = %v", err)
+	}
+	if result.Valid || len(result.Data["captions"].([]ReadingSpeed)) != 1 {
+		t.Errorf("Expected one caption over 300 WPM, got %+v", result.Data)
+	}
+
+	if _, err := ValidateReadingSpeed(captions, 0, 0); err == nil {
+		t.Error("Expected error when no limit is set")
+	}
+}
@@ -0,0 +1,229 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"caption-validator/internal/client"
+	"caption-validator/internal/parser"
+)
+
+// ErrSkipped is returned (wrapped) by a rule that could not run, for example
+// because the language API is unreachable. Callers log it and carry on.
+var ErrSkipped = errors.New("rule skipped")
+
+// ErrUnavailable is wrapped together with ErrSkipped when a rule could not run
+// because an external service it depends on failed, as opposed to not being
+// configured. Callers may treat it as an infrastructure problem. With
+// Context.Strict it is returned without ErrSkipped, so the rule errors.
+var ErrUnavailable = errors.New("service unavailable")
+
+// ErrNotApplicable is wrapped together with ErrSkipped when a rule does not
+// apply to the file, such as line limits that only the SCC format sets.
+// Callers need not report such skips as a problem.
+var ErrNotApplicable = errors.New("rule does not apply")
+
+// unavailable wraps the error of a service a rule depends on: the rule is
+// skipped, or errors in strict mode
+func unavailable(ctx Context, err error) error {
+	if ctx.Strict {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return fmt.Errorf("%w: %w: %v", ErrSkipped, ErrUnavailable, err)
+}
+
+// Severity says how much a failed rule matters
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// ParseSeverity validates a severity name. An empty name means SeverityError.
+func ParseSeverity(name string) (Severity, error) {
+	switch Severity(strings.ToLower(name)) {
+	case "", SeverityError:
+		return SeverityError, nil
+	case SeverityWarning:
+		return SeverityWarning, nil
+	case SeverityInfo:
+		return SeverityInfo, nil
+	}
+	return "", fmt.Errorf("unknown severity %q (want error, warning or info)", name)
+}
+
+// Context is everything a rule may inspect about one caption file
+type Context struct {
+	Captions  []parser.Caption
+	Format    string
+	StartTime float64 // start of the validated range, in seconds
+	EndTime   float64 // end of the validated range, in seconds
+
+	// ExpectedLanguage overrides the language rule's "expected" parameter
+	// when set, e.g. from a batch manifest
+	ExpectedLanguage string
+	// LanguageMatch overrides the language rule's "match" parameter when
+	// set, e.g. from -lang-match
+	LanguageMatch client.LanguageMatch
+	// ValidateLanguage checks caption text against an expected language.
+	// When nil, the language rule is skipped.
+	ValidateLanguage func(text string, expectedLang string) (client.LanguageValidationResult, error)
+	// Strict makes a rule whose service failed error instead of being
+	// skipped, so an unavailable language API fails the file
+	Strict bool
+}
+
+// Rule is a single caption check. Rules are created from a profile through
+// the registry and must be safe to run on many files concurrently.
+type Rule interface {
+	// Name is the name the rule is registered under
+	Name() string
+	// Check validates one file. It returns ErrSkipped (wrapped) when the
+	// rule cannot run, and any other error when the input is unusable.
+	Check(ctx Context) (ValidationResult, error)
+}
+
+// RuleFactory builds a rule from the parameters given in a profile
+type RuleFactory func(params Params) (Rule, error)
+
+var registry = make(map[string]RuleFactory)
+
+// Register makes a rule available to profiles under name. It panics if the
+// name is already taken, as two rules with one name is a programming error.
+func Register(name string, factory RuleFactory) {
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("validator: rule %q registered twice", name))
+	}
+	registry[name] = factory
+}
+
+// NewRule builds the rule registered under name
+func NewRule(name string, params Params) (Rule, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown rule %q (available: %s)", name, strings.Join(RuleNames(), ", "))
+	}
+	rule, err := factory(params)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: %w", name, err)
+	}
+	return rule, nil
+}
+
+// RuleNames lists the registered rules in alphabetical order
+func RuleNames() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Params holds a rule's parameters as read from a profile
+type Params map[string]interface{}
+
+// Float returns the named parameter as a number, or def if it is not set
+func (p Params) Float(name string, def float64) (float64, error) {
+	value, ok := p[name]
+	if !ok || value == nil {
+		return def, nil
+	}
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	}
+	return 0, fmt.Errorf("parameter %s must be a number, got %v", name, value)
+}
+
+// Int returns the named parameter as a whole number, or def if it is not set
+func (p Params) Int(name string, def int) (int, error) {
+	value, err := p.Float(name, float64(def))
+	if err != nil {
+		return 0, err
+	}
+	if value != float64(int(value)) {
+		return 0, fmt.Errorf("parameter %s must be a whole number, got %v", name, value)
+	}
+	return int(value), nil
+}
+
+// String returns the named parameter as text, or def if it is not set
+func (p Params) String(name string, def string) (string, error) {
+	value, ok := p[name]
+	if !ok || value == nil {
+		return def, nil
+	}
+	text, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("parameter %s must be a string, got %v", name, value)
+	}
+	return text, nil
+}
+
+// Strings returns the named parameter as a list, given either as a list of
+// strings or as one comma-separated string, or def if it is not set
+func (p Params) Strings(name string, def []string) ([]string, error) {
+	value, ok := p[name]
+	if !ok || value == nil {
+		return def, nil
+	}
+	var items []string
+	switch v := value.(type) {
+	case string:
+		items = strings.Split(v, ",")
+	case []string:
+		items = v
+	case []interface{}:
+		for _, item := range v {
+			text, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("parameter %s must be a list of strings, got %v", name, value)
+			}
+			items = append(items, text)
+		}
+	default:
+		return nil, fmt.Errorf("parameter %s must be a list of strings, got %v", name, value)
+	}
+
+	list := []string{}
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list, nil
+}
+
+// Only returns an error naming any parameter that is not in known, so typos
+// in a profile are reported instead of silently ignored
+func (p Params) Only(known ...string) error {
+	var unknown []string
+	for name := range p {
+		found := false
+		for _, k := range known {
+			if name == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown parameter(s) %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
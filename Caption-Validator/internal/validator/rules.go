@@ -0,0 +1,424 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"caption-validator/internal/client"
+	"caption-validator/internal/parser"
+)
+
+// Built-in rules, by the name used in profiles
+func init() {
+	Register("coverage", newCoverageRule)
+	Register("max_gap", newMaxGapRule)
+	Register("reading_speed", newReadingSpeedRule)
+	Register("line_length", newLineLengthRule)
+	Register("cue_timing", newCueTimingRule)
+	Register("cue_ordering", newCueOrderingRule)
+	Register("allowed_characters", newAllowedCharactersRule)
+	Register("language", newLanguageRule)
+}
+
+// coverageRule wraps ValidateCoverage. Params: min_coverage (percent, default 95).
+type coverageRule struct {
+	minCoverage float64
+}
+
+func newCoverageRule(params Params) (Rule, error) {
+	if err := params.Only("min_coverage"); err != nil {
+		return nil, err
+	}
+	minCoverage, err := params.Float("min_coverage", 95.0)
+	if err != nil {
+		return nil, err
+	}
+	return coverageRule{minCoverage: minCoverage}, nil
+}
+
+func (r coverageRule) Name() string { return "coverage" }
+
+func (r coverageRule) Check(ctx Context) (ValidationResult, error) {
+	return ValidateCoverage(ctx.Captions, ctx.StartTime, ctx.EndTime, r.minCoverage)
+}
+
+// maxGapRule wraps ValidateMaxGap. Params: max_gap (seconds, required).
+type maxGapRule struct {
+	maxGap float64
+}
+
+func newMaxGapRule(params Params) (Rule, error) {
+	if err := params.Only("max_gap"); err != nil {
+		return nil, err
+	}
+	maxGap, err := params.Float("max_gap", 0)
+	if err != nil {
+		return nil, err
+	}
+	if maxGap <= 0 {
+		return nil, fmt.Errorf("max_gap must be greater than zero")
+	}
+	return maxGapRule{maxGap: maxGap}, nil
+}
+
+func (r maxGapRule) Name() string { return "max_gap" }
+
+func (r maxGapRule) Check(ctx Context) (ValidationResult, error) {
+	return ValidateMaxGap(ctx.Captions, ctx.StartTime, ctx.EndTime, r.maxGap)
+}
+
+// readingSpeedRule wraps ValidateReadingSpeed. Params: max_cps, max_wpm (at least one).
+type readingSpeedRule struct {
+	maxCPS float64
+	maxWPM float64
+}
+
+func newReadingSpeedRule(params Params) (Rule, error) {
+	if err := params.Only("max_cps", "max_wpm"); err != nil {
+		return nil, err
+	}
+	maxCPS, err := params.Float("max_cps", 0)
+	if err != nil {
+		return nil, err
+	}
+	maxWPM, err := params.Float("max_wpm", 0)
+	if err != nil {
+		return nil, err
+	}
+	if maxCPS <= 0 && maxWPM <= 0 {
+		return nil, fmt.Errorf("max_cps or max_wpm must be set")
+	}
+	return readingSpeedRule{maxCPS: maxCPS, maxWPM: maxWPM}, nil
+}
+
+func (r readingSpeedRule) Name() string { return "reading_speed" }
+
+func (r readingSpeedRule) Check(ctx Context) (ValidationResult, error) {
+	return ValidateReadingSpeed(ctx.Captions, r.maxCPS, r.maxWPM)
+}
+
+// lineLengthRule wraps ValidateLineLength. Params: max_chars_per_line,
+// max_lines. A limit left unset defaults to the one of the file's format, see
+// FormatLineLimits; files whose format has none are skipped if neither is set.
+type lineLengthRule struct {
+	maxChars int
+	maxLines int
+}
+
+func newLineLengthRule(params Params) (Rule, error) {
+	if err := params.Only("max_chars_per_line", "max_lines"); err != nil {
+		return nil, err
+	}
+	maxChars, err := params.Int("max_chars_per_line", 0)
+	if err != nil {
+		return nil, err
+	}
+	maxLines, err := params.Int("max_lines", 0)
+	if err != nil {
+		return nil, err
+	}
+	if maxChars < 0 || maxLines < 0 {
+		return nil, fmt.Errorf("max_chars_per_line and max_lines must not be negative")
+	}
+	return lineLengthRule{maxChars: maxChars, maxLines: maxLines}, nil
+}
+
+func (r lineLengthRule) Name() string { return "line_length" }
+
+func (r lineLengthRule) Check(ctx Context) (ValidationResult, error) {
+	maxChars, maxLines := FormatLineLimits(ctx.Format)
+	if r.maxChars > 0 {
+		maxChars = r.maxChars
+	}
+	if r.maxLines > 0 {
+		maxLines = r.maxLines
+	}
+	if maxChars == 0 && maxLines == 0 {
+		return ValidationResult{}, fmt.Errorf("%w: %w: no line limits set for %s", ErrSkipped, ErrNotApplicable, ctx.Format)
+	}
+	return ValidateLineLength(ctx.Captions, maxChars, maxLines)
+}
+
+// cueTimingRule wraps ValidateTiming. Params: min_duration, max_duration and
+// min_gap in seconds, or min_gap_frames at frame_rate (default 29.97).
+type cueTimingRule struct {
+	limits TimingLimits
+}
+
+func newCueTimingRule(params Params) (Rule, error) {
+	if err := params.Only("min_duration", "max_duration", "min_gap", "min_gap_frames", "frame_rate"); err != nil {
+		return nil, err
+	}
+
+	var limits TimingLimits
+	var err error
+	if limits.MinDuration, err = params.Float("min_duration", 0); err != nil {
+		return nil, err
+	}
+	if limits.MaxDuration, err = params.Float("max_duration", 0); err != nil {
+		return nil, err
+	}
+	if limits.MinGap, err = params.Float("min_gap", 0); err != nil {
+		return nil, err
+	}
+
+	frames, err := params.Float("min_gap_frames", 0)
+	if err != nil {
+		return nil, err
+	}
+	frameRate, err := params.Float("frame_rate", 29.97)
+	if err != nil {
+		return nil, err
+	}
+	if frameRate <= 0 {
+		return nil, fmt.Errorf("frame_rate must be greater than zero")
+	}
+	if frames > 0 {
+		if limits.MinGap > 0 {
+			return nil, fmt.Errorf("set min_gap or min_gap_frames, not both")
+		}
+		limits.MinGap = FramesToSeconds(frames, frameRate)
+	}
+
+	return cueTimingRule{limits: limits}, nil
+}
+
+func (r cueTimingRule) Name() string { return "cue_timing" }
+
+func (r cueTimingRule) Check(ctx Context) (ValidationResult, error) {
+	return ValidateTiming(ctx.Captions, r.limits)
+}
+
+// cueOrderingRule wraps ValidateOrdering. It takes no params; cue numbers are
+// checked for SRT files only.
+type cueOrderingRule struct{}
+
+func newCueOrderingRule(params Params) (Rule, error) {
+	if err := params.Only(); err != nil {
+		return nil, err
+	}
+	return cueOrderingRule{}, nil
+}
+
+func (r cueOrderingRule) Name() string { return "cue_ordering" }
+
+func (r cueOrderingRule) Check(ctx Context) (ValidationResult, error) {
+	return ValidateOrdering(ctx.Captions, ctx.Format == parser.FormatSRT), nil
+}
+
+// allowedCharactersRule wraps ValidateCharacters. Params: charset (cea608,
+// ascii or latin; default latin) and extra, a string of further characters.
+type allowedCharactersRule struct {
+	charset string
+	allowed func(rune) bool
+}
+
+func newAllowedCharactersRule(params Params) (Rule, error) {
+	if err := params.Only("charset", "extra"); err != nil {
+		return nil, err
+	}
+	charset, err := params.String("charset", "latin")
+	if err != nil {
+		return nil, err
+	}
+	extra, err := params.String("extra", "")
+	if err != nil {
+		return nil, err
+	}
+
+	inCharset, ok := charsets[charset]
+	if !ok {
+		names := make([]string, 0, len(charsets))
+		for name := range charsets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown charset %q (available: %s)", charset, strings.Join(names, ", "))
+	}
+
+	allowed := inCharset
+	if extra != "" {
+		allowed = func(r rune) bool {
+			return inCharset(r) || strings.ContainsRune(extra, r)
+		}
+	}
+	return allowedCharactersRule{charset: charset, allowed: allowed}, nil
+}
+
+func (r allowedCharactersRule) Name() string { return "allowed_characters" }
+
+func (r allowedCharactersRule) Check(ctx Context) (ValidationResult, error) {
+	result := ValidateCharacters(ctx.Captions, r.allowed)
+	result.Data["charset"] = r.charset
+	return result, nil
+}
+
+// languageRule checks the language of the caption text through
+// Context.ValidateLanguage. Params: expected (a BCP 47 tag, default en-US),
+// match (exact, prefix or language, default exact), mode (file, cue or
+// window, default file), for window mode window (seconds, default 30), and
+// for cue and window modes min_chars (default 15), min_confidence (default
+// 0.5) and allow_languages. allow names the markup left out of the checked
+// text, in every mode; it defaults to foreign in cue and window modes and to
+// none in file mode, which checks all the text as it always has.
+type languageRule struct {
+	expected string
+	match    client.LanguageMatch
+	mode     string
+	segments LanguageSegmentOptions
+}
+
+// Language rule modes
+const (
+	languageModeFile   = "file"
+	languageModeCue    = "cue"
+	languageModeWindow = "window"
+)
+
+func newLanguageRule(params Params) (Rule, error) {
+	if err := params.Only("expected", "match", "mode", "window", "min_chars", "min_confidence", "allow", "allow_languages"); err != nil {
+		return nil, err
+	}
+	expected, err := params.String("expected", client.DefaultExpectedLanguage)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := client.ParseLanguageTag(expected)
+	if err != nil {
+		return nil, fmt.Errorf("parameter expected: %w", err)
+	}
+	matchName, err := params.String("match", string(client.MatchExact))
+	if err != nil {
+		return nil, err
+	}
+	match, err := client.ParseLanguageMatch(matchName)
+	if err != nil {
+		return nil, fmt.Errorf("parameter match: %w", err)
+	}
+
+	mode, err := params.String("mode", languageModeFile)
+	if err != nil {
+		return nil, err
+	}
+	var segments LanguageSegmentOptions
+	switch mode {
+	case languageModeFile, languageModeCue:
+	case languageModeWindow:
+		if segments.Window, err = params.Float("window", 30); err != nil {
+			return nil, err
+		}
+		if segments.Window <= 0 {
+			return nil, fmt.Errorf("window must be greater than zero")
+		}
+	default:
+		return nil, fmt.Errorf("unknown mode %q (available: %s, %s, %s)", mode, languageModeFile, languageModeCue, languageModeWindow)
+	}
+	if _, ok := params["window"]; ok && mode != languageModeWindow {
+		return nil, fmt.Errorf("window needs mode %s", languageModeWindow)
+	}
+	defaultAllow := []string{"foreign"}
+	if mode == languageModeFile {
+		for _, name := range []string{"min_chars", "min_confidence", "allow_languages"} {
+			if _, ok := params[name]; ok {
+				return nil, fmt.Errorf("%s needs mode %s or %s", name, languageModeCue, languageModeWindow)
+			}
+		}
+		defaultAllow = nil
+	}
+
+	if segments.MinLetters, err = params.Int("min_chars", 15); err != nil {
+		return nil, err
+	}
+	if segments.MinConfidence, err = params.Float("min_confidence", 0.5); err != nil {
+		return nil, err
+	}
+	if segments.MinConfidence < 0 || segments.MinConfidence > 1 {
+		return nil, fmt.Errorf("min_confidence must be between 0 and 1")
+	}
+	if segments.Allow, err = params.Strings("allow", defaultAllow); err != nil {
+		return nil, err
+	}
+	for _, name := range segments.Allow {
+		if _, ok := allowedMarkup[name]; !ok {
+			return nil, fmt.Errorf("unknown allow markup %q (available: %s)", name, strings.Join(AllowedMarkupNames(), ", "))
+		}
+	}
+	languages, err := params.Strings("allow_languages", nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, language := range languages {
+		allowed, err := client.ParseLanguageTag(language)
+		if err != nil {
+			return nil, fmt.Errorf("parameter allow_languages: %w", err)
+		}
+		segments.AllowLanguages = append(segments.AllowLanguages, allowed.String())
+	}
+
+	return languageRule{expected: tag.String(), match: match, mode: mode, segments: segments}, nil
+}
+
+func (r languageRule) Name() string { return "language" }
+
+func (r languageRule) Check(ctx Context) (ValidationResult, error) {
+	if ctx.ValidateLanguage == nil {
+		return ValidationResult{}, fmt.Errorf("%w: no language API configured", ErrSkipped)
+	}
+
+	expected := r.expected
+	if ctx.ExpectedLanguage != "" {
+		expected = client.CanonicalLanguageTag(ctx.ExpectedLanguage)
+	}
+	match := r.match
+	if ctx.LanguageMatch != "" {
+		match = ctx.LanguageMatch
+	}
+
+	if r.mode != languageModeFile {
+		opts := r.segments
+		opts.Expected, opts.Match = expected, match
+		result, err := ValidateLanguageSegments(ctx.Captions, opts, ctx.ValidateLanguage)
+		if err != nil {
+			return ValidationResult{}, unavailable(ctx, err)
+		}
+		result.Data["mode"] = r.mode
+		result.Data["recommendation"] = client.LanguageValidationResult{ExpectedLang: expected}.Recommendation()
+		return result, nil
+	}
+
+	texts := make([]string, 0, len(ctx.Captions))
+	for _, caption := range ctx.Captions {
+		if text := LanguageText(caption, r.segments.Allow); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	langResult, err := ctx.ValidateLanguage(strings.Join(texts, " "), expected)
+	if err != nil {
+		return ValidationResult{}, unavailable(ctx, err)
+	}
+	if langResult.Language == undeterminedLanguage {
+		return ValidationResult{}, fmt.Errorf("%w: the language of the captions could not be determined", ErrSkipped)
+	}
+	langResult.Valid = match.Matches(langResult.Language, langResult.ExpectedLang)
+
+	result := ValidationResult{
+		Valid: langResult.Valid,
+		Type:  "incorrect_language",
+		Data: map[string]interface{}{
+			"detected":       langResult.Language,
+			"expected":       langResult.ExpectedLang,
+			"recommendation": langResult.Recommendation(),
+			"match":          string(match),
+		},
+	}
+	if langResult.Confidence > 0 {
+		result.Data["confidence"] = langResult.Confidence
+	}
+	if !langResult.Valid {
+		result.Findings = []Finding{{
+			Message: fmt.Sprintf("Caption text was detected as %s, expected %s", langResult.Language, langResult.ExpectedLang),
+		}}
+	}
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+
+	"caption-validator/internal/parser"
+)
+
+// timingEpsilon absorbs floating point error in timestamps parsed from text,
+// so a cue that is exactly at a limit is not reported
+const timingEpsilon = 1e-6
+
+// TimingLimits configures ValidateTiming. Zero disables a limit; cues ending
+// before they start are always reported.
+type TimingLimits struct {
+	MinDuration float64 // seconds
+	MaxDuration float64 // seconds
+	MinGap      float64 // seconds between the end of a cue and the start of the next
+}
+
+// TimingFinding describes a cue that breaks a timing limit
+type TimingFinding struct {
+	Index     int     `json:"index"`
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+	Issue     string  `json:"issue"` // "end_before_start", "duration_too_short", "duration_too_long" or "gap_too_short"
+	Text      string  `json:"text"`
+	Duration  float64 `json:"duration"`
+	// For gap_too_short, the cue before this one and the gap between them
+	PreviousIndex int     `json:"previous_index,omitempty"`
+	Gap           float64 `json:"gap,omitempty"`
+}
+
+// FramesToSeconds converts a number of frames at the given frame rate to seconds
+func FramesToSeconds(frames float64, frameRate float64) float64 {
+	return frames / frameRate
+}
+
+// ValidateTiming checks each cue's duration and the gap to the cue before it.
+// Cues are compared in file order; overlapping cues are reported by ValidateOrdering.
+func ValidateTiming(captions []parser.Caption, limits TimingLimits) (ValidationResult, error) {
+	if limits.MinDuration < 0 || limits.MaxDuration < 0 || limits.MinGap < 0 {
+		return ValidationResult{}, fmt.Errorf("timing limits must not be negative")
+	}
+	if limits.MaxDuration > 0 && limits.MinDuration > limits.MaxDuration {
+		return ValidationResult{}, fmt.Errorf("minimum duration must not be greater than maximum duration")
+	}
+
+	findings := []TimingFinding{}
+	var located []Finding
+	for i, caption := range captions {
+		report := func(finding TimingFinding) {
+			findings = append(findings, finding)
+			located = append(located, Finding{
+				Message:  timingMessage(finding, limits),
+				Location: cueLocation(caption),
+				Details:  finding,
+			})
+		}
+
+		duration := caption.EndTime - caption.StartTime
+		finding := TimingFinding{
+			Index:     caption.Index,
+			StartTime: caption.StartTime,
+			EndTime:   caption.EndTime,
+			Text:      caption.PlainText(),
+			Duration:  roundMillis(duration),
+		}
+
+		switch {
+		case duration < 0:
+			finding.Issue = "end_before_start"
+			report(finding)
+			// The gap to a cue with no valid extent is meaningless
+			continue
+		case limits.MinDuration > 0 && duration < limits.MinDuration-timingEpsilon:
+			finding.Issue = "duration_too_short"
+			report(finding)
+		case limits.MaxDuration > 0 && duration > limits.MaxDuration+timingEpsilon:
+			finding.Issue = "duration_too_long"
+			report(finding)
+		}
+
+		if limits.MinGap > 0 && i > 0 {
+			previous := captions[i-1]
+			gap := caption.StartTime - previous.EndTime
+			if previous.EndTime >= previous.StartTime && gap >= 0 && gap < limits.MinGap-timingEpsilon {
+				finding.Issue = "gap_too_short"
+				finding.PreviousIndex = previous.Index
+				finding.Gap = roundMillis(gap)
+				report(finding)
+			}
+		}
+	}
+
+	result := ValidationResult{
+		Valid:    len(findings) == 0,
+		Findings: located,
+		Type:     "cue_timing",
+		Data: map[string]interface{}{
+			"captions": findings,
+		},
+	}
+
+	if limits.MinDuration > 0 {
+		result.Data["min_duration"] = limits.MinDuration
+	}
+	if limits.MaxDuration > 0 {
+		result.Data["max_duration"] = limits.MaxDuration
+	}
+	if limits.MinGap > 0 {
+		result.Data["min_gap"] = roundMillis(limits.MinGap)
+	}
+
+	return result, nil
+}
+
+// timingMessage describes a timing finding for people
+func timingMessage(finding TimingFinding, limits TimingLimits) string {
+	switch finding.Issue {
+	case "end_before_start":
+		return "Caption ends before it starts"
+	case "duration_too_short":
+		return fmt.Sprintf("Caption is shown for %.3f seconds, less than the minimum of %g", finding.Duration, limits.MinDuration)
+	case "duration_too_long":
+		return fmt.Sprintf("Caption is shown for %.3f seconds, more than the maximum of %g", finding.Duration, limits.MaxDuration)
+	default:
+		return fmt.Sprintf("Caption starts %.3f seconds after caption %d ends, less than the minimum gap of %.3f",
+			finding.Gap, finding.PreviousIndex, limits.MinGap)
+	}
+}
+
+// roundMillis rounds seconds to the nearest millisecond for reporting
+func roundMillis(seconds float64) float64 {
+	return math.Round(seconds*1000) / 1000
+}
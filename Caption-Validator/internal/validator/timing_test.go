@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"caption-validator/internal/parser"
+)
+
+func TestValidateTiming(t *testing.T) {
+	captions := []parser.Caption{
+		{Index: 1, StartTime: 0.0, EndTime: 2.0, Text: "Fine"},
+		{Index: 2, StartTime: 2.033, EndTime: 4.0, Text: "One frame after the last"},
+		{Index: 3, StartTime: 5.0, EndTime: 5.2, Text: "Flash"},
+		{Index: 4, StartTime: 6.0, EndTime: 36.0, Text: "Lingers"},
+		{Index: 5, StartTime: 40.0, EndTime: 39.0, Text: "Backwards"},
+		{Index: 6, StartTime: 41.0, EndTime: 42.0, Text: "Fine again"},
+		{Index: 7, StartTime: 42.08, EndTime: 43.0, Text: "Exactly two frames later"},
+	}
+
+	limits := TimingLimits{
+		MinDuration: 0.833,
+		MaxDuration: 7,
+		MinGap:      FramesToSeconds(2, 25),
+	}
+	result, err := ValidateTiming(captions, limits)
+	if err != nil {
+		t.Fatalf("ValidateTiming() error = %v", err)
+	}
+	if result.Valid || result.Type != "cue_timing" {
+		t.Errorf("Expected cue_timing failure, got %+v", result)
+	}
+
+	var jsonObj struct {
+		MinGap   float64         `json:"min_gap"`
+		Captions []TimingFinding `json:"captions"`
+	}
+	if err := json.Unmarshal([]byte(result.JSON()), &jsonObj); err != nil {
+		t.Fatalf("Failed to parse JSON result: %v", err)
+	}
+	if jsonObj.MinGap != 0.08 {
+		t.Errorf("min_gap = %v, want 0.08", jsonObj.MinGap)
+	}
+
+	want := []struct {
+		index int
+		issue string
+	}{
+		{2, "gap_too_short"},
+		{3, "duration_too_short"},
+		{4, "duration_too_long"},
+		{5, "end_before_start"},
+	}
+	if len(jsonObj.Captions) != len(want) {
+		t.Fatalf("Expected %d findings, got %+v", len(want), jsonObj.Captions)
+	}
+	for i, w := range want {
+		got := jsonObj.Captions[i]
+		if got.Index != w.index || got.Issue != w.issue {
+			t.Errorf("Finding %d = cue %d %s, want cue %d %s", i, got.Index, got.Issue, w.index, w.issue)
+		}
+	}
+	if gap := jsonObj.Captions[0]; gap.PreviousIndex != 1 || gap.Gap != 0.033 {
+		t.Errorf("Unexpected gap finding: %+v", gap)
+	}
+	if short := jsonObj.Captions[1]; short.Duration != 0.2 || short.Text != "Flash" {
+		t.Errorf("Unexpected duration finding: %+v", short)
+	}
+
+	// Only backwards cues are reported without limits
+	result, err = ValidateTiming(captions, TimingLimits{})
+	if err != nil {
+		t.Fatalf("ValidateTiming() error = %v", err)
+	}
+	if findings := result.Data["captions"].([]TimingFinding); len(findings) != 1 || findings[0].Issue != "end_before_start" {
+		t.Errorf("Expected only end_before_start, got %+v", findings)
+	}
+
+	if _, err := ValidateTiming(captions, TimingLimits{MinDuration: 5, MaxDuration: 2}); err == nil {
+		t.Error("Expected error when minimum duration exceeds maximum")
+	}
+}
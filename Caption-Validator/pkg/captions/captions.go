@@ -0,0 +1,124 @@
+// Package captions is the public API of the caption validator, for Go
+// programs that want to parse and validate caption files in-process rather
+// than run the caption-validator command.
+//
+// Parse and Detect read WebVTT, SRT, TTML/DFXP and Scenarist SCC captions
+// from any io.Reader. Validate runs the rules of a profile, the same rules
+// and profiles the command uses, and the individual checks such as
+// ValidateCoverage are available for callers that need just one.
+//
+// Types in this package are aliases of the validator's own types, so values
+// can be passed between this package and the validator freely and keep the
+// same JSON form as the command's reports.
+package captions
+
+import (
+	"bufio"
+	"io"
+
+	"caption-validator/internal/parser"
+)
+
+// Caption is a single caption: its index, its start and end time in seconds,
+// its text (with any inline markup) and its line in the source file
+type Caption = parser.Caption
+
+// Supported caption formats
+const (
+	FormatWebVTT = parser.FormatWebVTT
+	FormatSRT    = parser.FormatSRT
+	FormatTTML   = parser.FormatTTML
+	FormatSCC    = parser.FormatSCC
+)
+
+var (
+	// ErrUnsupportedFormat is returned (possibly wrapped) for captions in a
+	// format, or a format name, that is not supported
+	ErrUnsupportedFormat = parser.ErrUnsupportedFormat
+	// ErrAmbiguousFormat is returned (wrapped) when the content fits more
+	// than one format about equally well; pass the format to Parse instead
+	ErrAmbiguousFormat = parser.ErrAmbiguousFormat
+)
+
+// Detection is the result of sniffing the format of captions: the best
+// matching format, how confident the match is from 0 to 1, and every
+// candidate format with its score
+type Detection = parser.Detection
+
+// Candidate is one format captions might be in, with its score
+type Candidate = parser.Candidate
+
+// Parse parses captions in the given format from r. The format is one of the
+// Format constants or a name or file name that LookupFormat understands,
+// such as "vtt" or "episode1.srt". An empty format detects it from the
+// content, as ParseDetect does.
+func Parse(r io.Reader, format string) ([]Caption, error) {
+	if format == "" {
+		captions, _, err := ParseDetect(r)
+		return captions, err
+	}
+
+	resolved, ok := LookupFormat(format)
+	if !ok {
+		return nil, ErrUnsupportedFormat
+	}
+	return parser.ParseCaptions(r, resolved)
+}
+
+// ParseDetect detects the format of the captions in r from their content and
+// parses them, returning the format it detected
+func ParseDetect(r io.Reader) ([]Caption, string, error) {
+	return parser.ParseCaptionsReader(r)
+}
+
+// Detect determines the format of the captions in r from their content. It
+// reads at most the first 1KB of r; use ParseDetect to detect and parse in
+// one pass over a reader that cannot be rewound. Content that does not match
+// any format well enough is ErrUnsupportedFormat, and content that matches
+// two formats equally well wraps ErrAmbiguousFormat.
+func Detect(r io.Reader) (string, error) {
+	return parser.DetectFormat(r)
+}
+
+// PeekFormat determines the format of the captions at the start of br
+// without consuming them, so br can then be passed to Parse. It looks at up
+// to 1KB, or br's buffer size if that is smaller.
+func PeekFormat(br *bufio.Reader) (string, error) {
+	return parser.PeekFormat(br)
+}
+
+// Sniff scores the captions at the start of br against every known format
+// without consuming them, for callers that want the confidence rather than
+// just a verdict. name is used for its extension as a hint and may be "".
+// Detection.Result gives the verdict Detect would.
+func Sniff(br *bufio.Reader, name string) (Detection, error) {
+	return parser.SniffReader(br, name)
+}
+
+// ParseFile detects the format of a caption file and parses it. Unlike
+// ParseDetect it also uses the file's extension to recognise the format.
+func ParseFile(path string) ([]Caption, string, error) {
+	return parser.ParseCaptionsFile(path)
+}
+
+// LookupFormat resolves a format name such as "vtt" or "WebVTT", or a file
+// name with a known extension, to one of the Format constants
+func LookupFormat(name string) (string, bool) {
+	return parser.LookupFormat(name)
+}
+
+// Write serializes captions in the given format, which is resolved like
+// Parse's
+func Write(w io.Writer, captions []Caption, format string) error {
+	resolved, ok := LookupFormat(format)
+	if !ok {
+		return ErrUnsupportedFormat
+	}
+	return parser.WriteCaptions(w, captions, resolved)
+}
+
+// PlainText returns the text of all captions with markup removed, as sent
+// to language detection
+func PlainText(captions []Caption) string {
+	return parser.ExtractPlainText(captions)
+}
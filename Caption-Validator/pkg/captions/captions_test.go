@@ -0,0 +1,133 @@
+package captions_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"caption-validator/pkg/captions"
+)
+
+const webVTT = "WEBVTT\n\n1\n00:00:00.000 --> 00:00:10.000\nHello there\n\n2\n00:00:20.000 --> 00:00:30.000\nGeneral Kenobi\n"
+
+func TestParse(t *testing.T) {
+	for _, format := range []string{"vtt", "WebVTT", "episode1.vtt", ""} {
+		parsed, err := captions.Parse(strings.NewReader(webVTT), format)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %v", format, err)
+		}
+		if len(parsed) != 2 || parsed[1].Text != "General Kenobi" || parsed[1].Line != 8 {
+			t.Errorf("Parse(%q) = %+v", format, parsed)
+		}
+	}
+
+	if _, err := captions.Parse(strings.NewReader(webVTT), "docx"); !errors.Is(err, captions.ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat for an unknown format, got %v", err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	format, err := captions.Detect(strings.NewReader(webVTT))
+	if err != nil || format != captions.FormatWebVTT {
+		t.Errorf("Detect() = %q, %v, want %q", format, err, captions.FormatWebVTT)
+	}
+
+	parsed, format, err := captions.ParseDetect(strings.NewReader("1\n00:00:01,000 --> 00:00:02,000\nOne\n"))
+	if err != nil || format != captions.FormatSRT || len(parsed) != 1 {
+		t.Errorf("ParseDetect() = %+v, %q, %v", parsed, format, err)
+	}
+
+	br := bufio.NewReader(strings.NewReader(webVTT))
+	if format, err := captions.PeekFormat(br); err != nil || format != captions.FormatWebVTT {
+		t.Errorf("PeekFormat() = %q, %v, want %q", format, err, captions.FormatWebVTT)
+	}
+	if parsed, err := captions.Parse(br, captions.FormatWebVTT); err != nil || len(parsed) != 2 {
+		t.Errorf("Parse() after PeekFormat = %+v, %v", parsed, err)
+	}
+
+	detection, err := captions.Sniff(bufio.NewReader(strings.NewReader("00:00:01.000 --> 00:00:02.000\nOne\n")), "episode1.vtt")
+	if err != nil || detection.Format != captions.FormatWebVTT || detection.Confidence >= 1 || detection.Confidence < 0.5 {
+		t.Errorf("Sniff() = %+v, %v", detection, err)
+	}
+
+	if _, err := captions.Detect(strings.NewReader("Not captions")); !errors.Is(err, captions.ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	parsed, err := captions.Parse(strings.NewReader(webVTT), captions.FormatWebVTT)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := captions.Write(&buf, parsed, "srt"); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+	converted, err := captions.Parse(&buf, captions.FormatSRT)
+	if err != nil || len(converted) != 2 || converted[0].EndTime != 10 {
+		t.Errorf("Round trip through SRT = %+v, %v", converted, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	parsed, err := captions.Parse(strings.NewReader(webVTT), captions.FormatWebVTT)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	profile, err := captions.ParseProfile([]byte("rules:\n  - rule: coverage\n    params: {min_coverage: 90}\n  - rule: max_gap\n    severity: warning\n    params: {max_gap: 15}\n  - rule: language\n"))
+	if err != nil {
+		t.Fatalf("ParseProfile returned error: %v", err)
+	}
+
+	outcomes, err := captions.Validate(captions.Context{Captions: parsed, StartTime: 0, EndTime: 30}, profile)
+	if err != nil {
+		t.Fatalf("Validate returned error: %v", err)
+	}
+	if len(outcomes) != 3 {
+		t.Fatalf("Expected one outcome per rule, got %+v", outcomes)
+	}
+	if coverage := outcomes[0]; coverage.Result.Valid || len(coverage.Result.Findings) != 1 {
+		t.Errorf("Expected coverage to fail with one finding, got %+v", coverage)
+	}
+	if maxGap := outcomes[1]; !maxGap.Result.Valid || maxGap.Severity != captions.SeverityWarning {
+		t.Errorf("Expected max_gap to pass as a warning rule, got %+v", maxGap)
+	}
+	if language := outcomes[2]; !errors.Is(language.Err, captions.ErrSkipped) {
+		t.Errorf("Expected the language rule to be skipped without an API, got %+v", language)
+	}
+
+	// A detector of our own stands in for the language API
+	outcomes, err = captions.Validate(captions.Context{
+		Captions:         parsed,
+		StartTime:        0,
+		EndTime:          30,
+		ValidateLanguage: captions.LanguageValidator(frenchDetector{}),
+	}, profile)
+	if err != nil {
+		t.Fatalf("Validate returned error: %v", err)
+	}
+	if language := outcomes[2]; language.Err != nil || language.Result.Valid || len(language.Result.Findings) != 1 {
+		t.Errorf("Expected the language rule to fail for French captions, got %+v", language)
+	}
+
+	if _, ok := captions.BuiltinProfile("streaming"); !ok {
+		t.Error("Expected the streaming built-in profile")
+	}
+
+	gaps, err := captions.FindGaps(parsed, 0, 30)
+	if err != nil || len(gaps) != 1 || gaps[0].Start != 10 || gaps[0].End != 20 {
+		t.Errorf("FindGaps() = %+v, %v", gaps, err)
+	}
+}
+
+// frenchDetector detects every text as French
+type frenchDetector struct{}
+
+func (frenchDetector) DetectLanguage(text string) (captions.LanguageDetection, error) {
+	return captions.LanguageDetection{Language: "fr-FR", Confidence: 1}, nil
+}
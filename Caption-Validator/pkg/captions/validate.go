@@ -0,0 +1,269 @@
+package captions
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"caption-validator/internal/client"
+	"caption-validator/internal/langid"
+	"caption-validator/internal/validator"
+)
+
+// Profile selects the rules a file is checked against, with their parameters
+// and severities. It is read from YAML or JSON by ParseProfile and
+// LoadProfile, or taken from the built-in profiles.
+type Profile = validator.Profile
+
+// RuleConfig enables one rule in a profile
+type RuleConfig = validator.RuleConfig
+
+// Params holds a rule's parameters
+type Params = validator.Params
+
+// Severity says how much a failed rule matters
+type Severity = validator.Severity
+
+// Severities a rule may be configured with
+const (
+	SeverityError   = validator.SeverityError
+	SeverityWarning = validator.SeverityWarning
+	SeverityInfo    = validator.SeverityInfo
+)
+
+// Context is everything the rules may inspect about one caption file
+type Context = validator.Context
+
+// Rule is a single caption check; see Register
+type Rule = validator.Rule
+
+// RuleFactory builds a rule from the parameters given in a profile
+type RuleFactory = validator.RuleFactory
+
+// Engine runs the rules of a profile. It is safe to reuse for many files.
+type Engine = validator.Engine
+
+// Outcome is the result of running one rule of a profile on a file
+type Outcome = validator.Outcome
+
+// ValidationResult is what a check found. Findings lists each problem with
+// its location in the file.
+type ValidationResult = validator.ValidationResult
+
+// Finding is one problem reported by a failed check
+type Finding = validator.Finding
+
+// Location points at the part of a caption file a finding is about
+type Location = validator.Location
+
+// Gap is an interval of the validated range with no caption on screen
+type Gap = validator.Gap
+
+// LanguageRange is the details of a language finding in the language rule's
+// cue and window modes: a stretch of captions in an unexpected language
+type LanguageRange = validator.LanguageRange
+
+// TimingLimits configures ValidateTiming
+type TimingLimits = validator.TimingLimits
+
+// LanguageResult is the outcome of checking caption text against an
+// expected language, as returned by Context.ValidateLanguage
+type LanguageResult = client.LanguageValidationResult
+
+// LanguageDetector identifies the language caption text is written in.
+// Implement it to plug in another backend, or a fake in tests, and pass it
+// to LanguageValidator.
+type LanguageDetector = client.LanguageDetector
+
+// LanguageDetection is a LanguageDetector's verdict: a BCP 47 tag and, if
+// the detector reports one, a confidence from 0 to 1
+type LanguageDetection = client.LanguageDetection
+
+// RetryPolicy says how often and how patiently the HTTP detector repeats
+// requests that fail with a network error or a busy status such as 503
+type RetryPolicy = client.RetryPolicy
+
+// DefaultRetryPolicy retries twice with exponential backoff and jitter
+var DefaultRetryPolicy = client.DefaultRetryPolicy
+
+// CircuitBreaker stops the HTTP detector calling an API that keeps failing
+type CircuitBreaker = client.CircuitBreaker
+
+// LanguageMatch says how closely a detected language must match the
+// expected one; set it as Context.LanguageMatch or the language rule's
+// "match" parameter
+type LanguageMatch = client.LanguageMatch
+
+// Language matching modes
+const (
+	MatchExact    = client.MatchExact
+	MatchPrefix   = client.MatchPrefix
+	MatchLanguage = client.MatchLanguage
+)
+
+var (
+	// ErrSkipped is wrapped by the error of a rule that could not run, such
+	// as the language rule without a language API
+	ErrSkipped = validator.ErrSkipped
+	// ErrUnavailable is wrapped together with ErrSkipped when the service a
+	// rule depends on failed, or returned alone when Context.Strict is set
+	ErrUnavailable = validator.ErrUnavailable
+	// ErrNotApplicable is wrapped together with ErrSkipped when a rule does
+	// not apply to the file's format, such as line limits outside SCC
+	ErrNotApplicable = validator.ErrNotApplicable
+	// ErrCircuitOpen is the error of a language API call refused by an open
+	// CircuitBreaker
+	ErrCircuitOpen = client.ErrCircuitOpen
+)
+
+// NewEngine builds the enabled rules of a profile
+func NewEngine(profile Profile) (*Engine, error) {
+	return validator.NewEngine(profile)
+}
+
+// Validate checks captions against every rule of a profile, in profile order.
+// ctx.Captions, ctx.StartTime and ctx.EndTime must be set. Callers that
+// validate many files with one profile should build an Engine once instead.
+func Validate(ctx Context, profile Profile) ([]Outcome, error) {
+	engine, err := validator.NewEngine(profile)
+	if err != nil {
+		return nil, err
+	}
+	return engine.Run(ctx), nil
+}
+
+// ParseProfile reads a YAML or JSON profile
+func ParseProfile(data []byte) (Profile, error) {
+	return validator.ParseProfile(data)
+}
+
+// LoadProfile reads a YAML or JSON profile file
+func LoadProfile(path string) (Profile, error) {
+	return validator.LoadProfile(path)
+}
+
+// BuiltinProfile returns the built-in delivery profile called name, such as
+// "dcmp", "streaming" or "ebu"
+func BuiltinProfile(name string) (Profile, bool) {
+	return validator.BuiltinProfile(name)
+}
+
+// BuiltinProfileNames lists the built-in delivery profiles
+func BuiltinProfileNames() []string {
+	return validator.BuiltinProfileNames()
+}
+
+// Register makes a custom rule available to profiles under name. It panics
+// if a rule is already registered under that name.
+func Register(name string, factory RuleFactory) {
+	validator.Register(name, factory)
+}
+
+// LanguageValidator returns a Context.ValidateLanguage function that checks
+// text with detector
+func LanguageValidator(detector LanguageDetector) func(text string, expectedLang string) (LanguageResult, error) {
+	return func(text string, expectedLang string) (LanguageResult, error) {
+		return client.ValidateLanguageWith(detector, text, expectedLang)
+	}
+}
+
+// LanguageValidatorContext is LanguageValidator for calls that ctx can
+// cancel, e.g. when the service shuts down
+func LanguageValidatorContext(ctx context.Context, detector LanguageDetector) func(text string, expectedLang string) (LanguageResult, error) {
+	return func(text string, expectedLang string) (LanguageResult, error) {
+		return client.ValidateLanguageContext(ctx, detector, text, expectedLang)
+	}
+}
+
+// NewHTTPDetector returns the detector that calls the language validation
+// API at apiURL, retrying with DefaultRetryPolicy. httpClient may be nil.
+func NewHTTPDetector(httpClient *http.Client, apiURL string) LanguageDetector {
+	return client.NewHTTPDetector(httpClient, apiURL)
+}
+
+// NewHTTPDetectorWithRetry is NewHTTPDetector with its own retry policy and
+// circuit breaker. Share one breaker between the detectors of a service so
+// that they all stop calling an API that is down; breaker may be nil.
+func NewHTTPDetectorWithRetry(httpClient *http.Client, apiURL string, retry RetryPolicy, breaker *CircuitBreaker) LanguageDetector {
+	detector := client.NewHTTPDetector(httpClient, apiURL)
+	detector.Retry = retry
+	detector.Breaker = breaker
+	return detector
+}
+
+// NewCircuitBreaker returns a breaker that refuses calls for cooldown after
+// threshold consecutive failures, then lets one call test the service.
+// A threshold of 0 returns nil, which never opens.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return client.NewCircuitBreaker(threshold, cooldown)
+}
+
+// BuiltinDetector returns the offline detector, which identifies 66
+// languages from their script and character trigrams. It reports bare
+// language tags such as "fr", so use MatchPrefix or MatchLanguage with it.
+func BuiltinDetector() LanguageDetector {
+	return langid.New()
+}
+
+// LanguageAPI returns a Context.ValidateLanguage function that checks text
+// with the language validation API at apiURL. httpClient may be nil.
+func LanguageAPI(httpClient *http.Client, apiURL string) func(text string, expectedLang string) (LanguageResult, error) {
+	return LanguageValidator(NewHTTPDetector(httpClient, apiURL))
+}
+
+// CanonicalLanguageTag returns a BCP 47 tag in canonical form, e.g. "en-GB"
+// for "en_gb", or tag unchanged if it is not valid
+func CanonicalLanguageTag(tag string) string {
+	return client.CanonicalLanguageTag(tag)
+}
+
+// LanguageFromFilename infers the language of a caption file from names
+// like episode1.fr.vtt or episode1.pt-BR.srt
+func LanguageFromFilename(path string) (string, bool) {
+	return client.LanguageFromFilename(path)
+}
+
+// FindGaps returns the uncaptioned intervals between startTime and endTime
+func FindGaps(captions []Caption, startTime float64, endTime float64) ([]Gap, error) {
+	return validator.FindGaps(captions, startTime, endTime)
+}
+
+// ValidateCoverage checks that captions are on screen for at least
+// minCoverage percent of the range between startTime and endTime
+func ValidateCoverage(captions []Caption, startTime float64, endTime float64, minCoverage float64) (ValidationResult, error) {
+	return validator.ValidateCoverage(captions, startTime, endTime, minCoverage)
+}
+
+// ValidateMaxGap checks that no uncaptioned interval between startTime and
+// endTime is longer than maxGap seconds
+func ValidateMaxGap(captions []Caption, startTime float64, endTime float64, maxGap float64) (ValidationResult, error) {
+	return validator.ValidateMaxGap(captions, startTime, endTime, maxGap)
+}
+
+// ValidateReadingSpeed checks captions against a maximum number of
+// characters per second and words per minute; 0 disables a limit
+func ValidateReadingSpeed(captions []Caption, maxCPS float64, maxWPM float64) (ValidationResult, error) {
+	return validator.ValidateReadingSpeed(captions, maxCPS, maxWPM)
+}
+
+// ValidateLineLength checks the characters per line and the lines per
+// caption; 0 disables a limit
+func ValidateLineLength(captions []Caption, maxChars int, maxLines int) (ValidationResult, error) {
+	return validator.ValidateLineLength(captions, maxChars, maxLines)
+}
+
+// ValidateTiming checks caption durations and the gaps between captions
+func ValidateTiming(captions []Caption, limits TimingLimits) (ValidationResult, error) {
+	return validator.ValidateTiming(captions, limits)
+}
+
+// ValidateOrdering checks that captions are in time order without overlaps,
+// and that their indices are sequential when checkIndices is set
+func ValidateOrdering(captions []Caption, checkIndices bool) ValidationResult {
+	return validator.ValidateOrdering(captions, checkIndices)
+}
+
+// ValidateCharacters reports characters for which allowed returns false
+func ValidateCharacters(captions []Caption, allowed func(rune) bool) ValidationResult {
+	return validator.ValidateCharacters(captions, allowed)
+}
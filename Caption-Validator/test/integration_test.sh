@@ -1,10 +1,16 @@
 #!/bin/bash
-# Integration test for batch-validate.sh
+# Integration test for the caption-validator batch subcommand
 
 # Setup test environment
 TEST_DIR="$(dirname "$0")/test_batch"
+RESULTS_FILE="validation_results.json"
+BIN="./caption-validator"
 mkdir -p "$TEST_DIR"
 
+# Build the validator
+echo "Building caption-validator..."
+go build -o "$BIN" ../cmd/ || exit 1
+
 # Create test caption files
 echo "Creating test caption files..."
 
@@ -76,66 +82,73 @@ EOF
 
 echo "Running tests..."
 
+# file_valid prints the summary "passed" value reported for a file in the batch results
+file_valid() {
+    awk -v name="$1" 'index($0, "\"file\": ") && index($0, name "\"") { found = 1 }
+        found && /"passed":/ { gsub(/[ ,]/, ""); split($0, kv, ":"); print kv[2]; exit }' "$RESULTS_FILE"
+}
+
 # Test 1: Test with 100% required coverage (should fail for partial coverage)
 echo "Test 1: Testing with 100% required coverage"
-../batch-validate.sh "$TEST_DIR" 60 100
-if [ $? -eq 0 ]; then
-    echo "✓ Test 1 passed: Script executed successfully"
-    if grep -q "partial_coverage.vtt" validation_results.json; then
+"$BIN" batch -t_end 60 -coverage 100 -api "" "$TEST_DIR" > "$RESULTS_FILE"
+if [ $? -eq 1 ]; then
+    echo "✓ Test 1 passed: Batch exited 1 for the coverage failure"
+    if [ "$(file_valid partial_coverage.vtt)" = "false" ]; then
         echo "✓ Test 1 passed: Partial coverage file was flagged"
     else
         echo "✗ Test 1 failed: Partial coverage file was not flagged"
         exit 1
     fi
 else
-    echo "✗ Test 1 failed: Script execution failed"
+    echo "✗ Test 1 failed: Batch execution failed"
     exit 1
 fi
 
-# Test 2: Test with 80% required coverage (partial coverage file should pass)
+# Test 2: Test with 80% required coverage (good coverage file should pass)
 echo "Test 2: Testing with 80% required coverage"
-../batch-validate.sh "$TEST_DIR" 60 80
+"$BIN" batch -t_end 60 -coverage 80 -api "" -fail-on none "$TEST_DIR" > "$RESULTS_FILE"
 if [ $? -eq 0 ]; then
-    echo "✓ Test 2 passed: Script executed successfully"
-    if grep -q "partial_coverage.vtt" validation_results.json; then
-        echo "✗ Test 2 failed: Partial coverage file was incorrectly flagged at 80% threshold"
-        exit 1
+    echo "✓ Test 2 passed: Batch executed successfully"
+    if [ "$(file_valid good_coverage.srt)" = "true" ]; then
+        echo "✓ Test 2 passed: Good coverage file passed at 80% threshold"
     else
-        echo "✓ Test 2 passed: Partial coverage file passed at 80% threshold"
+        echo "✗ Test 2 failed: Good coverage file was incorrectly flagged at 80% threshold"
+        exit 1
     fi
 else
-    echo "✗ Test 2 failed: Script execution failed"
+    echo "✗ Test 2 failed: Batch execution failed"
     exit 1
 fi
 
 # Test 3: Test with language validation
 echo "Test 3: Testing language validation"
 # Start mock language API with force detection enabled
-cd ../mock_language_api && go run main.go --force-detect &
+(cd ../mock_language_api && go build -o "$OLDPWD/mock-language-api" .) || exit 1
+./mock-language-api --force-detect 2>/dev/null &
 MOCK_API_PID=$!
 sleep 1
-cd -
 
-../batch-validate.sh "$TEST_DIR" 60 90 "http://localhost:8080/validate"
+"$BIN" batch -t_end 60 -coverage 90 -api "http://localhost:8080/validate" -fail-on none "$TEST_DIR" > "$RESULTS_FILE"
 if [ $? -eq 0 ]; then
-    echo "✓ Test 3 passed: Script executed successfully with language validation"
-    if grep -q "spanish.vtt" validation_results.json && grep -q "incorrect_language" validation_results.json; then
+    echo "✓ Test 3 passed: Batch executed successfully with language validation"
+    if [ "$(file_valid spanish.vtt)" = "false" ] && grep -q "incorrect_language" "$RESULTS_FILE"; then
         echo "✓ Test 3 passed: Spanish file was flagged for language"
     else
         echo "✗ Test 3 failed: Spanish file was not correctly identified"
         # Don't exit here as this might be a flaky test depending on the mock API
     fi
 else
-    echo "✗ Test 3 failed: Script execution failed with language validation"
+    echo "✗ Test 3 failed: Batch execution failed with language validation"
     exit 1
 fi
 
 # Kill the mock API
 kill $MOCK_API_PID
+rm -f ./mock-language-api
 
 # Test 4: Test with extended time format
 echo "Test 4: Testing with extended time format"
-../batch-validate.sh "$TEST_DIR" 1m 90
+"$BIN" batch -t_end 1m -coverage 90 -api "" -fail-on none "$TEST_DIR" > "$RESULTS_FILE"
 if [ $? -eq 0 ]; then
     echo "✓ Test 4 passed: Extended time format accepted"
 else
@@ -143,6 +156,37 @@ else
     exit 1
 fi
 
+# Test 5: Unsupported files named on the command line are reported and fail
+# the batch with an I/O exit code
+echo "Test 5: Testing unsupported file reporting"
+"$BIN" batch -t_end 25m -coverage 90 -api "" sample_episodes sample_episodes/unsupported.xml > "$RESULTS_FILE"
+if [ $? -eq 3 ] && grep -q "unsupported_format" "$RESULTS_FILE"; then
+    echo "✓ Test 5 passed: Unsupported files were reported"
+else
+    echo "✗ Test 5 failed: Unsupported files were not reported"
+    exit 1
+fi
+
+# Test 6: JUnit output for CI dashboards
+echo "Test 6: Testing JUnit output"
+"$BIN" batch -t_end 60 -coverage 100 -api "" -format junit "$TEST_DIR" > "$RESULTS_FILE"
+if [ $? -eq 1 ] && grep -q '<testsuite name=".*partial_coverage.vtt"' "$RESULTS_FILE" && grep -q '<failure message="Captions cover' "$RESULTS_FILE"; then
+    echo "✓ Test 6 passed: Coverage failure reported as a JUnit failure"
+else
+    echo "✗ Test 6 failed: JUnit output did not report the coverage failure"
+    exit 1
+fi
+
+# Test 7: Bad flags are usage errors
+echo "Test 7: Testing usage error exit code"
+"$BIN" batch -t_end 60 -fail-on info -api "" "$TEST_DIR" > "$RESULTS_FILE"
+if [ $? -eq 2 ]; then
+    echo "✓ Test 7 passed: Unknown -fail-on value exited 2"
+else
+    echo "✗ Test 7 failed: Unknown -fail-on value did not exit 2"
+    exit 1
+fi
+
 echo "All tests completed."
 
 # Optional: Clean up test files
@@ -11,7 +11,7 @@ import (
 )
 
 // generateLargeCaptionFile creates a test caption file with the specified number of captions
-func generateLargeCaptionFile(t *testing.T, numCaptions int, format string) string {
+func generateLargeCaptionFile(t testing.TB, numCaptions int, format string) string {
 	t.Helper()
 	
 	var extension, header, captionTemplate string